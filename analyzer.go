@@ -1,21 +1,30 @@
 package wsl
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"go/ast"
 	"go/token"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
 )
 
-const version = "wsl version v5.9.0"
+// Version is the version of wsl.
+const Version = "v5.9.0"
+
+const version = "wsl version " + Version
 
 func NewAnalyzer(config *Configuration) *analysis.Analyzer {
 	wa := &wslAnalyzer{config: config}
+	wa.changedLines = sync.OnceValues(wa.loadChangedLines)
 
 	return &analysis.Analyzer{
 		Name:             "wsl",
@@ -23,6 +32,7 @@ func NewAnalyzer(config *Configuration) *analysis.Analyzer {
 		Flags:            wa.flags(),
 		Run:              wa.run,
 		RunDespiteErrors: true,
+		Requires:         []*analysis.Analyzer{inspect.Analyzer},
 	}
 }
 
@@ -38,15 +48,39 @@ type wslAnalyzer struct {
 	defaultChecks string
 	enable        []string
 	disable       []string
+	severities    []string
+	configFile    string
+	newFromRev    string
+	newFromPatch  string
+
+	// reportDroppedFixes reports a diagnostic for each fix that was dropped
+	// because it would result in invalid code.
+	reportDroppedFixes bool
+
+	// changedLines returns the lines changed when running with -new-from-rev
+	// or -new-from-patch. The diff is only loaded once for all packages.
+	changedLines func() (changedLines, error)
+
+	// setFlags holds the names of all flags explicitly set on the command line
+	// since those have precedence over values from a configuration file.
+	setFlags map[string]struct{}
 
-	// To only validate and convert the parsed flags once we use a `sync.Once`
-	// to only create a check set once and store the set and potential error. We
-	// also store if we actually had a configuration to ensure we don't
-	// overwrite the checks if the analyzer was created with a proper wsl
-	// config.
-	cfgOnce       sync.Once
+	// We store if we actually had a configuration to ensure we don't overwrite
+	// the checks if the analyzer was created with a proper wsl config.
 	didHaveConfig bool
-	checkSetErr   error
+
+	// Each package can find a different configuration file so we load each
+	// configuration file once and cache the result by path. Since overrides
+	// can apply to a subset of the files, the resolved configurations are
+	// cached by the path and the overrides matching the file.
+	configsMu   sync.Mutex
+	configFiles map[string]configFileResult
+	configs     map[string]*Configuration
+}
+
+type configFileResult struct {
+	file *ConfigFile
+	err  error
 }
 
 func (wa *wslAnalyzer) flags() flag.FlagSet {
@@ -66,36 +100,218 @@ func (wa *wslAnalyzer) flags() flag.FlagSet {
 	flags.IntVar(&wa.config.CaseMaxLines, "case-max-lines", 0, "Max lines before requiring a newline at the end of case (0 = never)")
 	flags.IntVar(&wa.config.CuddleMaxStatements, "cuddle-max-statements", 1, "Max number of cuddled statements above statements")
 
+	flags.BoolVar(&wa.config.SyntaxOnly, "syntax-only", false, "Ignore type information and use heuristics based on names")
+	flags.BoolVar(&wa.config.ErrCheckSelector, "err-check-selector", false, "Include error fields, e.g. `if res.Err != nil`, in the err check")
+	flags.BoolVar(&wa.config.ErrCheckIsAs, "err-check-is-as", false, "Include `errors.Is` and `errors.As` calls in the err check")
+	flags.BoolVar(&wa.config.ErrCheckCommaOk, "err-check-comma-ok", false, "Include `if !ok` after comma-ok assignments in the err check")
+	flags.Var(&multiStringValue{slicePtr: &wa.config.LockMethods}, "lock-methods", "Comma separated list of methods that can be cuddled above any statement")
+	flags.Var(&multiStringValue{slicePtr: &wa.config.UnlockMethods}, "unlock-methods", "Comma separated list of methods that can be cuddled below any statement")
+	flags.Var(&multiStringValue{slicePtr: &wa.config.LockTypes}, "lock-types", "Comma separated list of types, e.g. 'sync.Locker', to restrict lock and unlock methods to")
+
 	flags.StringVar(&wa.defaultChecks, "default", "", "Can be 'all' for all checks or 'none' for no checks or empty for default checks")
 	flags.Var(&multiStringValue{slicePtr: &wa.enable}, "enable", "Comma separated list of checks to enable")
 	flags.Var(&multiStringValue{slicePtr: &wa.disable}, "disable", "Comma separated list of checks to disable")
+	flags.Var(&multiStringValue{slicePtr: &wa.severities}, "severity", "Comma separated list of `check=severity` where severity is 'error', 'warning', 'info' or 'off'")
+	flags.StringVar(&wa.configFile, "config", "", "Path to configuration file, if not set "+strings.Join(ConfigFileNames, ", ")+" is searched for from the package directory and up")
+	flags.StringVar(&wa.newFromRev, "new-from-rev", "", "Only report issues on lines changed since this git revision")
+	flags.StringVar(&wa.newFromPatch, "new-from-patch", "", "Only report issues on lines changed in this unified diff, '-' to read from stdin")
+	flags.BoolVar(&wa.reportDroppedFixes, "report-dropped-fixes", false, "Report fixes dropped since they would result in invalid code or lost comments")
 	flags.Var(new(versionFlag), "V", "print version and exit")
 
+	wa.setFlags = map[string]struct{}{}
+
+	flags.VisitAll(func(f *flag.Flag) {
+		f.Value = &trackedFlagValue{Value: f.Value, name: f.Name, setFlags: wa.setFlags}
+	})
+
 	return *flags
 }
 
-func (wa *wslAnalyzer) run(pass *analysis.Pass) (any, error) {
-	wa.cfgOnce.Do(func() {
-		// No need to update checks if config was passed when creating the
-		// analyzer.
-		if wa.didHaveConfig {
-			return
+// configFileForPass returns the configuration file to use for the pass, if
+// any. If the analyzer was created with a configuration no file is used.
+func (wa *wslAnalyzer) configFileForPass(pass *analysis.Pass) (*ConfigFile, error) {
+	if wa.didHaveConfig {
+		return nil, nil //nolint:nilnil // No file is used.
+	}
+
+	path := wa.configFile
+	if path == "" {
+		for _, file := range pass.Files {
+			filename := getFilename(pass.Fset, file)
+			if !strings.HasSuffix(filename, ".go") {
+				continue
+			}
+
+			var err error
+
+			path, err = FindConfigFile(filepath.Dir(filename))
+			if err != nil {
+				return nil, err
+			}
+
+			break
 		}
+	}
 
-		// Parse the check params once if we set our config from flags.
-		wa.config.Checks, wa.checkSetErr = NewCheckSet(wa.defaultChecks, wa.enable, wa.disable)
-	})
+	if path == "" {
+		return nil, nil //nolint:nilnil // No file is found.
+	}
+
+	wa.configsMu.Lock()
+	defer wa.configsMu.Unlock()
+
+	if wa.configFiles == nil {
+		wa.configFiles = map[string]configFileResult{}
+	}
+
+	result, ok := wa.configFiles[path]
+	if !ok {
+		result.file, result.err = LoadConfigFile(path)
+		wa.configFiles[path] = result
+	}
+
+	return result.file, result.err
+}
+
+// configForFile returns the configuration to use for the file. If the analyzer
+// was created with a configuration it's always used, otherwise the flags are
+// applied on top of the configuration file and the overrides matching the
+// file.
+func (wa *wslAnalyzer) configForFile(cf *ConfigFile, filename string) (*Configuration, error) {
+	if wa.didHaveConfig {
+		return wa.config, nil
+	}
+
+	var key string
+	if cf != nil {
+		key = fmt.Sprintf("%s%v", cf.Path, cf.matchingOverrides(filename))
+	}
+
+	wa.configsMu.Lock()
+	defer wa.configsMu.Unlock()
+
+	if cfg, ok := wa.configs[key]; ok {
+		return cfg, nil
+	}
+
+	cfg, err := wa.resolveConfig(cf, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if wa.configs == nil {
+		wa.configs = map[string]*Configuration{}
+	}
+
+	wa.configs[key] = cfg
+
+	return cfg, nil
+}
+
+// resolveConfig creates a configuration for the file from the configuration
+// file (if not nil) and applies all explicitly set flags on top of it.
+func (wa *wslAnalyzer) resolveConfig(cf *ConfigFile, filename string) (*Configuration, error) {
+	cfg := NewConfig()
+
+	if cf != nil {
+		if err := cf.ApplyFor(cfg, filename); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, apply := range map[string]func(){
+		"include-generated":     func() { cfg.IncludeGenerated = wa.config.IncludeGenerated },
+		"allow-first-in-block":  func() { cfg.AllowFirstInBlock = wa.config.AllowFirstInBlock },
+		"allow-whole-block":     func() { cfg.AllowWholeBlock = wa.config.AllowWholeBlock },
+		"branch-max-lines":      func() { cfg.BranchMaxLines = wa.config.BranchMaxLines },
+		"case-max-lines":        func() { cfg.CaseMaxLines = wa.config.CaseMaxLines },
+		"cuddle-max-statements": func() { cfg.CuddleMaxStatements = wa.config.CuddleMaxStatements },
+		"syntax-only":           func() { cfg.SyntaxOnly = wa.config.SyntaxOnly },
+		"err-check-selector":    func() { cfg.ErrCheckSelector = wa.config.ErrCheckSelector },
+		"err-check-is-as":       func() { cfg.ErrCheckIsAs = wa.config.ErrCheckIsAs },
+		"err-check-comma-ok":    func() { cfg.ErrCheckCommaOk = wa.config.ErrCheckCommaOk },
+		"lock-methods":          func() { cfg.LockMethods = wa.config.LockMethods },
+		"unlock-methods":        func() { cfg.UnlockMethods = wa.config.UnlockMethods },
+		"lock-types":            func() { cfg.LockTypes = wa.config.LockTypes },
+	} {
+		if wa.isSet(name) {
+			apply()
+		}
+	}
+
+	// A preset from the flags replaces all checks from the file, otherwise the
+	// checks enabled or disabled with flags are added on top of them.
+	if wa.isSet("default") {
+		checks, err := NewCheckSet(wa.defaultChecks, wa.enable, wa.disable)
+		if err != nil {
+			return nil, err
+		}
 
-	if wa.checkSetErr != nil {
-		return nil, wa.checkSetErr
+		cfg.Checks = checks
+	} else if err := cfg.Checks.update(wa.enable, wa.disable); err != nil {
+		return nil, err
 	}
 
+	for _, lockType := range cfg.LockTypes {
+		if _, _, err := ParseLockType(lockType); err != nil {
+			return nil, err
+		}
+	}
+
+	severities, err := NewSeverities(wa.severities)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.setSeverities(severities)
+
+	return cfg, nil
+}
+
+func (wa *wslAnalyzer) isSet(name string) bool {
+	_, ok := wa.setFlags[name]
+	return ok
+}
+
+// loadChangedLines returns the changed lines from the revision or patch passed
+// as flags, or nil if issues on all lines should be reported.
+func (wa *wslAnalyzer) loadChangedLines() (changedLines, error) {
+	switch {
+	case wa.newFromRev != "" && wa.newFromPatch != "":
+		return nil, errors.New("-new-from-rev and -new-from-patch can't be used together")
+	case wa.newFromRev != "":
+		return changedLinesFromRev(wa.newFromRev)
+	case wa.newFromPatch != "":
+		return changedLinesFromPatch(wa.newFromPatch)
+	default:
+		return nil, nil //nolint:nilnil // Issues on all lines are reported.
+	}
+}
+
+func (wa *wslAnalyzer) run(pass *analysis.Pass) (any, error) {
+	configFile, err := wa.configFileForPass(pass)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := wa.changedLines()
+	if err != nil {
+		return nil, err
+	}
+
+	insp, _ := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
 	for _, file := range pass.Files {
 		filename := getFilename(pass.Fset, file)
 		if !strings.HasSuffix(filename, ".go") {
 			continue
 		}
 
+		config, err := wa.configForFile(configFile, filename)
+		if err != nil {
+			return nil, err
+		}
+
 		// if the file is related to cgo the filename of the unadjusted position
 		// is a not a '.go' file.
 		unadjustedFilename := pass.Fset.PositionFor(file.Pos(), false).Filename
@@ -109,34 +325,61 @@ func (wa *wslAnalyzer) run(pass *analysis.Pass) (any, error) {
 		// The file is skipped if the "unadjusted" file is a Go file, and it's a
 		// generated file (ex: "_test.go" file). The other non-Go files are
 		// skipped by the first 'if' with the adjusted position.
-		if !wa.config.IncludeGenerated && ast.IsGenerated(file) {
+		if !config.IncludeGenerated && ast.IsGenerated(file) {
 			continue
 		}
 
-		wsl := New(file, pass, wa.config)
-		wsl.Run()
+		issues := lint(pass.Fset, file, pass.TypesInfo, pass.Pkg, insp, config)
+
+		// Without the source we can't validate the fixes but we still report
+		// them.
+		if src, err := readFile(pass, unadjustedFilename); err == nil {
+			dropped := validateFixes(pass.Fset, file, src, issues)
+			if wa.reportDroppedFixes {
+				for _, d := range dropped {
+					if changes != nil && !changes.includes(pass.Fset, d.issue) {
+						continue
+					}
+
+					pass.Report(analysis.Diagnostic{
+						Pos:      d.issue.Pos,
+						Category: d.issue.CheckType.String(),
+						URL:      d.issue.CheckType.DocURL(),
+						Message:  fmt.Sprintf("dropped invalid fix for %s: %v", d.issue.CheckType, d.err),
+					})
+				}
+			}
+		}
 
-		for pos, fix := range wsl.issues {
-			textEdits := []analysis.TextEdit{}
+		for _, issue := range issues {
+			if changes != nil && !changes.includes(pass.Fset, issue) {
+				continue
+			}
 
-			for _, f := range fix.fixRanges {
-				textEdits = append(textEdits, analysis.TextEdit{
-					Pos:     f.fixRangeStart,
-					End:     f.fixRangeEnd,
-					NewText: f.fix,
-				})
+			diagnostic := analysis.Diagnostic{
+				Pos:      issue.Pos,
+				Category: issue.CheckType.String(),
+				URL:      diagnosticURL(issue.CheckType, issue.Reason),
+				Message:  severityMessage(issue.Severity, issue.Message),
 			}
 
-			pass.Report(analysis.Diagnostic{
-				Pos:      pos,
-				Category: "whitespace",
-				Message:  fix.message,
-				SuggestedFixes: []analysis.SuggestedFix{
-					{
-						TextEdits: textEdits,
-					},
-				},
-			})
+			// Issues sharing a fix with a previous issue has no edits of their
+			// own.
+			if len(issue.Edits) > 0 {
+				textEdits := make([]analysis.TextEdit, 0, len(issue.Edits))
+
+				for _, e := range issue.Edits {
+					textEdits = append(textEdits, analysis.TextEdit{
+						Pos:     e.Pos,
+						End:     e.End,
+						NewText: e.NewText,
+					})
+				}
+
+				diagnostic.SuggestedFixes = []analysis.SuggestedFix{{TextEdits: textEdits}}
+			}
+
+			pass.Report(diagnostic)
 		}
 	}
 
@@ -144,6 +387,33 @@ func (wa *wslAnalyzer) run(pass *analysis.Pass) (any, error) {
 	return nil, nil
 }
 
+// readFile reads the file with the driver's file reader if there is one.
+func readFile(pass *analysis.Pass, filename string) ([]byte, error) {
+	if pass.ReadFile == nil {
+		return os.ReadFile(filename)
+	}
+
+	return pass.ReadFile(filename)
+}
+
+// diagnosticURL returns the URL to the section documenting the check with the
+// code for the reason as the `reason` query parameter, e.g.
+// `CHECKS.md?reason=no-intersection#if`, so the reason can be read without
+// parsing the message.
+func diagnosticURL(check CheckType, reason Reason) string {
+	return checksDocURL + "?" + url.Values{"reason": {reason.String()}}.Encode() + "#" + check.docSection()
+}
+
+// severityMessage prefixes the message with the severity unless it's an error.
+// Errors are left as is to keep the output backwards compatible.
+func severityMessage(severity Severity, message string) string {
+	if severity == SeverityError {
+		return message
+	}
+
+	return fmt.Sprintf("%s: %s", severity, message)
+}
+
 // multiStringValue is a flag that supports multiple values. It's implemented to
 // contain a pointer to a string slice that will be overwritten when the flag's
 // `Set` method is called.
@@ -175,6 +445,37 @@ func (m *multiStringValue) String() string {
 	return strings.Join(*m.slicePtr, ", ")
 }
 
+// trackedFlagValue wraps a flag value to record if the flag was explicitly set.
+type trackedFlagValue struct {
+	flag.Value
+
+	name     string
+	setFlags map[string]struct{}
+}
+
+// Set implements the flag.Value interface and records that the flag was set.
+func (t *trackedFlagValue) Set(value string) error {
+	t.setFlags[t.name] = struct{}{}
+
+	return t.Value.Set(value)
+}
+
+// String implements the flag.Value interface. The flag package calls this on
+// a zero value to find the default so we must handle a nil value.
+func (t *trackedFlagValue) String() string {
+	if t.Value == nil {
+		return ""
+	}
+
+	return t.Value.String()
+}
+
+// IsBoolFlag makes wrapped boolean flags work without a value.
+func (t *trackedFlagValue) IsBoolFlag() bool {
+	b, ok := t.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
 // https://cs.opensource.google/go/x/tools/+/refs/tags/v0.35.0:go/analysis/internal/analysisflags/flags.go;l=188-237;drc=99337ebe7b90918701a41932abf121600b972e34
 type versionFlag string
 
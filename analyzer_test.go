@@ -1,10 +1,14 @@
 package wsl
 
 import (
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/tools/go/analysis/analysistest"
 )
 
@@ -37,6 +41,65 @@ func TestDefaultConfigFromAnalyzer(t *testing.T) {
 	analysistest.RunWithSuggestedFixes(t, testdata, analyzer, filepath.Join("default_config", "if"))
 }
 
+func TestConfigFileFromAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	analyzer := NewAnalyzer(nil)
+
+	analysistest.RunWithSuggestedFixes(t, testdata, analyzer, "config_file")
+}
+
+func TestConfigFileOverrides(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	analyzer := NewAnalyzer(nil)
+
+	analysistest.RunWithSuggestedFixes(t, testdata, analyzer, "config_overrides/strict", "config_overrides/relaxed")
+}
+
+func TestNewFromPatch(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	analyzer := NewAnalyzer(nil)
+
+	require.NoError(t, analyzer.Flags.Set("new-from-patch", filepath.Join(testdata, "src", "new_from_patch", "changes.patch")))
+
+	analysistest.RunWithSuggestedFixes(t, testdata, analyzer, "new_from_patch")
+}
+
+func TestDiagnosticMetadata(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	analyzer := NewAnalyzer(NewConfig())
+
+	results := analysistest.Run(t, testdata, analyzer, filepath.Join("default_config", "if"))
+	require.NotEmpty(t, results)
+
+	for _, result := range results {
+		require.NotEmpty(t, result.Diagnostics)
+
+		for _, diagnostic := range result.Diagnostics {
+			check, err := CheckFromString(diagnostic.Category)
+			require.NoError(t, err)
+
+			u, err := url.Parse(diagnostic.URL)
+			require.NoError(t, err)
+
+			reason, err := ReasonFromString(u.Query().Get("reason"))
+			require.NoError(t, err)
+
+			assert.Equal(t, diagnosticURL(check, reason), diagnostic.URL)
+			assert.Equal(t, check.docSection(), u.Fragment)
+			assert.True(t, strings.HasPrefix(diagnostic.URL, checksDocURL+"?"))
+			assert.NotContains(t, diagnostic.Message, "["+reason.String()+"]")
+		}
+	}
+}
+
 func TestWithConfig(t *testing.T) {
 	t.Parallel()
 
@@ -46,6 +109,38 @@ func TestWithConfig(t *testing.T) {
 		subdir   string
 		configFn func(*Configuration)
 	}{
+		{
+			subdir: "err_check_extended",
+			configFn: func(config *Configuration) {
+				config.ErrCheckSelector = true
+				config.ErrCheckIsAs = true
+				config.ErrCheckCommaOk = true
+			},
+		},
+		{
+			subdir:   "err_check_default",
+			configFn: func(*Configuration) {},
+		},
+		{
+			subdir: "syntax_only",
+			configFn: func(config *Configuration) {
+				config.SyntaxOnly = true
+				config.ErrCheckIsAs = true
+			},
+		},
+		{
+			subdir: "lock_methods",
+			configFn: func(config *Configuration) {
+				config.LockMethods = []string{"Lock", "Acquire"}
+				config.UnlockMethods = []string{"Unlock", "Release"}
+			},
+		},
+		{
+			subdir: "lock_types",
+			configFn: func(config *Configuration) {
+				config.LockTypes = []string{"sync.Locker", "with_config/lock_types.Semaphore"}
+			},
+		},
 		{
 			subdir: "no_check_decl",
 			configFn: func(config *Configuration) {
@@ -174,6 +269,53 @@ func TestWithConfig(t *testing.T) {
 				config.Checks.Add(CheckCuddleGroup)
 			},
 		},
+		{
+			subdir: "severity",
+			configFn: func(config *Configuration) {
+				config.Checks.Add(CheckAfterBlock)
+				config.Severities = Severities{
+					CheckAfterBlock: SeverityWarning,
+					CheckReturn:     SeverityInfo,
+					CheckAssign:     SeverityOff,
+				}
+			},
+		},
+		{
+			subdir: "directives",
+			configFn: func(config *Configuration) {
+				config.Checks.Add(CheckUnusedDirective)
+			},
+		},
+		{
+			subdir: "top_level_decl",
+			configFn: func(config *Configuration) {
+				config.Checks = NoChecks()
+				config.Checks.Add(CheckTopLevelDecl)
+			},
+		},
+		{
+			subdir: "decl_group",
+			configFn: func(config *Configuration) {
+				config.Checks = NoChecks()
+				config.Checks.Add(CheckDeclGroup)
+			},
+		},
+		{
+			subdir: "type_whitespace",
+			configFn: func(config *Configuration) {
+				config.Checks = NoChecks()
+				config.Checks.Add(CheckTypeWhitespace)
+				config.Checks.Add(CheckEmbeddedField)
+			},
+		},
+		{
+			subdir: "composite_call",
+			configFn: func(config *Configuration) {
+				config.Checks = NoChecks()
+				config.Checks.Add(CheckCompositeWhitespace)
+				config.Checks.Add(CheckCallWhitespace)
+			},
+		},
 		{
 			subdir: "cuddle_group_max_2",
 			configFn: func(config *Configuration) {
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+const jsonrpcVersion = "2.0"
+
+// JSON-RPC error codes used by the server.
+const (
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// message is a JSON-RPC request, notification or response. Requests have an
+// ID and a method, notifications only a method and responses only an ID.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// conn reads and writes JSON-RPC messages with the `Content-Length` header
+// framing used by LSP.
+type conn struct {
+	r *textproto.Reader
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{
+		r: textproto.NewReader(bufio.NewReader(r)),
+		w: w,
+	}
+}
+
+func (c *conn) read() (*message, error) {
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r.R, body); err != nil {
+		return nil, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+
+	return &msg, nil
+}
+
+func (c *conn) write(msg *message) error {
+	msg.JSONRPC = jsonrpcVersion
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+
+	_, err = c.w.Write(body)
+
+	return err
+}
+
+func (c *conn) notify(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return c.write(&message{Method: method, Params: raw})
+}
+
+func (c *conn) request(id int, method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	rawID := json.RawMessage(strconv.Itoa(id))
+
+	return c.write(&message{ID: &rawID, Method: method, Params: raw})
+}
+
+func (c *conn) reply(id *json.RawMessage, result any, err error) error {
+	msg := &message{ID: id}
+
+	if err != nil {
+		var respErr *responseError
+		if !errors.As(err, &respErr) {
+			respErr = &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+
+		msg.Error = respErr
+
+		return c.write(msg)
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	msg.Result = raw
+
+	return c.write(msg)
+}
@@ -0,0 +1,52 @@
+// Command wsl-lsp is a language server publishing wsl diagnostics and quick
+// fixes for editors without support for gopls analyzers. It communicates over
+// stdin and stdout.
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/bombsimon/wsl/v5"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	var (
+		configFile string
+		syntaxOnly bool
+		logFile    string
+	)
+
+	flag.StringVar(&configFile, "config", "", "Path to configuration file, if not set "+strings.Join(wsl.ConfigFileNames, ", ")+" is searched for from the document directory and up")
+	flag.BoolVar(&syntaxOnly, "syntax-only", false, "Don't type check documents, use heuristics based on names instead")
+	flag.StringVar(&logFile, "log", "", "Path to log file, logs are discarded if not set")
+	flag.Parse()
+
+	var logOutput io.Writer = io.Discard
+
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		logOutput = f
+	}
+
+	s := newServer(os.Stdin, os.Stdout, log.New(logOutput, "", log.LstdFlags))
+	s.configFile = configFile
+	s.syntaxOnly = syntaxOnly
+
+	return s.run()
+}
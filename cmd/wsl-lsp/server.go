@@ -0,0 +1,598 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/bombsimon/wsl/v5"
+)
+
+const (
+	serverName       = "wsl-lsp"
+	diagnosticSource = "wsl"
+	fixAllTitle      = "Fix all wsl issues in file"
+)
+
+// errExit is returned from handle when the client sends `exit`.
+var errExit = errors.New("exit")
+
+// document is an open text document.
+type document struct {
+	uri     string
+	path    string
+	version int
+	text    []byte
+}
+
+// configEntry is a loaded configuration file. The modification time is used
+// to reload the file if it changes even if the client doesn't notify us.
+type configEntry struct {
+	file    *wsl.ConfigFile
+	modTime int64
+}
+
+type server struct {
+	conn   *conn
+	logger *log.Logger
+
+	// configFile is the configuration file to use for all documents. If empty,
+	// the configuration file is searched for from the document directory.
+	configFile string
+	syntaxOnly bool
+
+	mu        sync.Mutex
+	documents map[string]*document
+	configs   map[string]configEntry
+	shutdown  bool
+	requestID int
+}
+
+func newServer(r io.Reader, w io.Writer, logger *log.Logger) *server {
+	return &server{
+		conn:      newConn(r, w),
+		logger:    logger,
+		documents: map[string]*document{},
+		configs:   map[string]configEntry{},
+	}
+}
+
+// run reads and handles messages until the client sends `exit` or the
+// connection is closed.
+func (s *server) run() error {
+	for {
+		msg, err := s.conn.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.handle(msg); errors.Is(err, errExit) {
+			return nil
+		} else if err != nil {
+			s.logger.Printf("failed to handle %s: %v", msg.Method, err)
+		}
+	}
+}
+
+func (s *server) handle(msg *message) error {
+	// Responses to our own requests, e.g. `client/registerCapability`.
+	if msg.Method == "" {
+		if msg.Error != nil {
+			s.logger.Printf("request %s failed: %v", string(*msg.ID), msg.Error)
+		}
+
+		return nil
+	}
+
+	var (
+		result any
+		err    error
+	)
+
+	if s.shutdown && msg.Method != methodExit {
+		if msg.ID == nil {
+			return nil
+		}
+
+		return s.conn.reply(msg.ID, nil, &responseError{Code: codeInvalidRequest, Message: "server is shut down"})
+	}
+
+	switch msg.Method {
+	case methodInitialize:
+		result, err = s.initialize(msg.Params)
+	case methodInitialized, methodDidChangeConfiguration:
+		// Nothing to do, configuration is read from the configuration files.
+	case methodShutdown:
+		s.shutdown = true
+	case methodExit:
+		return errExit
+	case methodDidOpen:
+		err = s.didOpen(msg.Params)
+	case methodDidChange:
+		err = s.didChange(msg.Params)
+	case methodDidSave:
+		err = s.didSave(msg.Params)
+	case methodDidClose:
+		err = s.didClose(msg.Params)
+	case methodDidChangeWatchedFiles:
+		err = s.didChangeWatchedFiles(msg.Params)
+	case methodCodeAction:
+		result, err = s.codeAction(msg.Params)
+	default:
+		if msg.ID == nil {
+			// Unknown notifications are ignored.
+			return nil
+		}
+
+		err = &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+
+	if msg.ID == nil {
+		return err
+	}
+
+	return s.conn.reply(msg.ID, result, err)
+}
+
+func (s *server) initialize(raw json.RawMessage) (*initializeResult, error) {
+	var params initializeParams
+	if err := unmarshalParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	// Watch the configuration files so we can reload them. If the client
+	// doesn't support it, changes are still picked up on the next lint since
+	// we check the modification time.
+	if params.Capabilities.Workspace.DidChangeWatchedFiles.DynamicRegistration {
+		watchers := []fileSystemWatcher{}
+		for _, name := range wsl.ConfigFileNames {
+			watchers = append(watchers, fileSystemWatcher{GlobPattern: "**/" + name, Kind: watchKindAll})
+		}
+
+		s.requestID++
+
+		if err := s.conn.request(s.requestID, methodRegisterCapability, registrationParams{
+			Registrations: []registration{{
+				ID:              "wsl-config-files",
+				Method:          methodDidChangeWatchedFiles,
+				RegisterOptions: didChangeWatchedFilesRegistrationOptions{Watchers: watchers},
+			}},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync: textDocumentSyncOptions{
+				OpenClose: true,
+				Change:    textDocumentSyncKindFull,
+				Save:      true,
+			},
+			CodeActionProvider: codeActionOptions{
+				CodeActionKinds: []string{codeActionKindQuickFix, codeActionKindSourceFixAll},
+			},
+		},
+		ServerInfo: serverInfo{Name: serverName},
+	}, nil
+}
+
+func (s *server) didOpen(raw json.RawMessage) error {
+	var params didOpenTextDocumentParams
+	if err := unmarshalParams(raw, &params); err != nil {
+		return err
+	}
+
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+
+	doc := &document{
+		uri:     params.TextDocument.URI,
+		path:    path,
+		version: params.TextDocument.Version,
+		text:    []byte(params.TextDocument.Text),
+	}
+
+	s.mu.Lock()
+	s.documents[doc.uri] = doc
+	s.mu.Unlock()
+
+	return s.publishDiagnostics(doc)
+}
+
+func (s *server) didChange(raw json.RawMessage) error {
+	var params didChangeTextDocumentParams
+	if err := unmarshalParams(raw, &params); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	doc, ok := s.documents[params.TextDocument.URI]
+
+	// We use full document sync so the last change is the whole document.
+	if ok && len(params.ContentChanges) > 0 {
+		doc.version = params.TextDocument.Version
+		doc.text = []byte(params.ContentChanges[len(params.ContentChanges)-1].Text)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("unknown document %s", params.TextDocument.URI)
+	}
+
+	return s.publishDiagnostics(doc)
+}
+
+func (s *server) didSave(raw json.RawMessage) error {
+	var params didSaveTextDocumentParams
+	if err := unmarshalParams(raw, &params); err != nil {
+		return err
+	}
+
+	// Saving a configuration file open in the editor reloads the
+	// configuration for all documents.
+	if isConfigFile(params.TextDocument.URI) {
+		return s.reloadConfig()
+	}
+
+	return nil
+}
+
+func (s *server) didClose(raw json.RawMessage) error {
+	var params didCloseTextDocumentParams
+	if err := unmarshalParams(raw, &params); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.documents, params.TextDocument.URI)
+	s.mu.Unlock()
+
+	// Clear the diagnostics for the closed document.
+	return s.conn.notify(methodPublishDiagnostics, publishDiagnosticsParams{
+		URI:         params.TextDocument.URI,
+		Diagnostics: []diagnostic{},
+	})
+}
+
+func (s *server) didChangeWatchedFiles(raw json.RawMessage) error {
+	var params didChangeWatchedFilesParams
+	if err := unmarshalParams(raw, &params); err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(params.Changes, func(e fileEvent) bool { return isConfigFile(e.URI) }) {
+		return s.reloadConfig()
+	}
+
+	return nil
+}
+
+// reloadConfig drops all loaded configuration files and publishes new
+// diagnostics for all open documents.
+func (s *server) reloadConfig() error {
+	s.mu.Lock()
+	s.configs = map[string]configEntry{}
+
+	documents := make([]*document, 0, len(s.documents))
+	for _, doc := range s.documents {
+		documents = append(documents, doc)
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(documents, func(a, b *document) int {
+		return strings.Compare(a.uri, b.uri)
+	})
+
+	var errs []error
+
+	for _, doc := range documents {
+		errs = append(errs, s.publishDiagnostics(doc))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *server) codeAction(raw json.RawMessage) ([]codeAction, error) {
+	var params codeActionParams
+	if err := unmarshalParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	doc, ok := s.documents[params.TextDocument.URI]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown document %s", params.TextDocument.URI)
+	}
+
+	wantKind := func(kind string) bool {
+		if len(params.Context.Only) == 0 {
+			return true
+		}
+
+		return slices.ContainsFunc(params.Context.Only, func(only string) bool {
+			return kind == only || strings.HasPrefix(kind, only+".")
+		})
+	}
+
+	cfg, err := s.config(doc.path)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, file, err := lint(doc, cfg)
+	if err != nil {
+		// The document doesn't parse so there's nothing to fix.
+		return []codeAction{}, nil //nolint:nilerr // Not an error for the client.
+	}
+
+	actions := []codeAction{}
+
+	if wantKind(codeActionKindQuickFix) {
+		for _, issue := range issues {
+			if len(issue.Edits) == 0 {
+				continue
+			}
+
+			diag := toDiagnostic(doc.text, file, issue)
+			if !rangesOverlap(diag.Range, params.Range) {
+				continue
+			}
+
+			edits := make([]textEdit, 0, len(issue.Edits))
+			for _, e := range issue.Edits {
+				edits = append(edits, textEdit{
+					Range: lspRange{
+						Start: offsetToPosition(doc.text, file.Offset(e.Pos)),
+						End:   offsetToPosition(doc.text, file.Offset(e.End)),
+					},
+					NewText: string(e.NewText),
+				})
+			}
+
+			actions = append(actions, codeAction{
+				Title:       "Fix: " + issue.Message,
+				Kind:        codeActionKindQuickFix,
+				Diagnostics: []diagnostic{diag},
+				IsPreferred: true,
+				Edit:        &workspaceEdit{Changes: map[string][]textEdit{doc.uri: edits}},
+			})
+		}
+	}
+
+	if wantKind(codeActionKindSourceFixAll) && slices.ContainsFunc(issues, func(i wsl.Issue) bool { return len(i.Edits) > 0 }) {
+		formatted, err := wsl.FormatFile(doc.path, doc.text, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		actions = append(actions, codeAction{
+			Title: fixAllTitle,
+			Kind:  codeActionKindSourceFixAll,
+			Edit: &workspaceEdit{Changes: map[string][]textEdit{
+				doc.uri: {{
+					Range: lspRange{
+						Start: position{},
+						End:   offsetToPosition(doc.text, len(doc.text)),
+					},
+					NewText: string(formatted),
+				}},
+			}},
+		})
+	}
+
+	return actions, nil
+}
+
+func (s *server) publishDiagnostics(doc *document) error {
+	diagnostics := []diagnostic{}
+
+	cfg, err := s.config(doc.path)
+	if err != nil {
+		// Show invalid configuration files at the top of the document so the
+		// user knows why no other diagnostics are shown.
+		diagnostics = append(diagnostics, diagnostic{
+			Severity: diagnosticSeverityError,
+			Source:   diagnosticSource,
+			Message:  err.Error(),
+		})
+	} else if issues, file, err := lint(doc, cfg); err == nil {
+		for _, issue := range issues {
+			diagnostics = append(diagnostics, toDiagnostic(doc.text, file, issue))
+		}
+	}
+
+	return s.conn.notify(methodPublishDiagnostics, publishDiagnosticsParams{
+		URI:         doc.uri,
+		Version:     doc.version,
+		Diagnostics: diagnostics,
+	})
+}
+
+// config returns the configuration for the file at path. The configuration
+// file is found from the directory of the file unless set with -config.
+func (s *server) config(path string) (*wsl.Configuration, error) {
+	configPath := s.configFile
+	if configPath == "" {
+		var err error
+
+		configPath, err = wsl.FindConfigFile(filepath.Dir(path))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := wsl.NewConfig()
+
+	if configPath != "" {
+		cf, err := s.loadConfigFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := cf.ApplyFor(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.syntaxOnly {
+		cfg.SyntaxOnly = true
+	}
+
+	return cfg, nil
+}
+
+func (s *server) loadConfigFile(path string) (*wsl.ConfigFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	modTime := info.ModTime().UnixNano()
+
+	s.mu.Lock()
+	entry, ok := s.configs[path]
+	s.mu.Unlock()
+
+	if ok && entry.modTime == modTime {
+		return entry.file, nil
+	}
+
+	cf, err := wsl.LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.configs[path] = configEntry{file: cf, modTime: modTime}
+	s.mu.Unlock()
+
+	return cf, nil
+}
+
+// lint returns the issues for the document and the file the positions are in.
+func lint(doc *document, cfg *wsl.Configuration) ([]wsl.Issue, *token.File, error) {
+	fset := token.NewFileSet()
+
+	issues, err := wsl.LintSource(fset, doc.path, doc.text, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var file *token.File
+
+	fset.Iterate(func(f *token.File) bool {
+		file = f
+		return false
+	})
+
+	return issues, file, nil
+}
+
+func toDiagnostic(text []byte, file *token.File, issue wsl.Issue) diagnostic {
+	pos := offsetToPosition(text, file.Offset(issue.Pos))
+
+	severity := diagnosticSeverityError
+
+	switch issue.Severity {
+	case wsl.SeverityWarning:
+		severity = diagnosticSeverityWarning
+	case wsl.SeverityInfo:
+		severity = diagnosticSeverityInformation
+	}
+
+	return diagnostic{
+		Range:           lspRange{Start: pos, End: pos},
+		Severity:        severity,
+		Code:            issue.Reason.String(),
+		CodeDescription: &codeDescription{Href: issue.CheckType.DocURL()},
+		Source:          diagnosticSource,
+		Message:         issue.Message,
+	}
+}
+
+// offsetToPosition converts a byte offset to a position where the character
+// is counted in UTF-16 code units as required by LSP.
+func offsetToPosition(text []byte, offset int) position {
+	offset = min(offset, len(text))
+
+	var pos position
+
+	lineStart := 0
+
+	for i := range offset {
+		if text[i] == '\n' {
+			pos.Line++
+			lineStart = i + 1
+		}
+	}
+
+	for line := text[lineStart:offset]; len(line) > 0; {
+		r, size := utf8.DecodeRune(line)
+		pos.Character += utf16.RuneLen(r)
+		line = line[size:]
+	}
+
+	return pos
+}
+
+func rangesOverlap(a, b lspRange) bool {
+	return !positionLess(a.End, b.Start) && !positionLess(b.End, a.Start)
+}
+
+func positionLess(a, b position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+
+	return a.Character < b.Character
+}
+
+func isConfigFile(uri string) bool {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(wsl.ConfigFileNames, filepath.Base(path))
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme '%s'", u.Scheme)
+	}
+
+	return filepath.FromSlash(u.Path), nil
+}
+
+func unmarshalParams(raw json.RawMessage, v any) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	a := 1
+	if true {
+		fmt.Println("cuddled")
+	}
+	err := errors.New("x")
+
+	if err != nil {
+		panic(err)
+	}
+
+	_ = a
+}
+`
+
+const formatted = `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	a := 1
+
+	if true {
+		fmt.Println("cuddled")
+	}
+
+	err := errors.New("x")
+	if err != nil {
+		panic(err)
+	}
+
+	_ = a
+}
+`
+
+// client is an in-process LSP client talking to the server over pipes, the
+// same way an editor does over stdio.
+type client struct {
+	t        *testing.T
+	conn     *conn
+	messages chan *message
+	nextID   int
+	done     chan error
+}
+
+func newClient(t *testing.T, configure func(*server)) *client {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	s := newServer(serverIn, serverOut, log.New(io.Discard, "", 0))
+	if configure != nil {
+		configure(s)
+	}
+
+	c := &client{
+		t:        t,
+		conn:     newConn(clientIn, clientOut),
+		messages: make(chan *message, 100),
+		done:     make(chan error, 1),
+	}
+
+	go func() {
+		c.done <- s.run()
+
+		serverOut.Close()
+	}()
+
+	go func() {
+		defer close(c.messages)
+
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				return
+			}
+
+			c.messages <- msg
+		}
+	}()
+
+	t.Cleanup(func() {
+		clientOut.Close()
+	})
+
+	return c
+}
+
+func (c *client) next() *message {
+	c.t.Helper()
+
+	select {
+	case msg, ok := <-c.messages:
+		require.True(c.t, ok, "connection closed")
+		return msg
+	case <-time.After(30 * time.Second):
+		c.t.Fatal("timeout waiting for message")
+	}
+
+	return nil
+}
+
+// call sends a request and returns the response. Notifications received
+// while waiting are not expected.
+func (c *client) call(method string, params, result any) *responseError {
+	c.t.Helper()
+
+	c.nextID++
+	require.NoError(c.t, c.conn.request(c.nextID, method, params))
+
+	msg := c.next()
+	require.Empty(c.t, msg.Method, "expected response, got %s", msg.Method)
+	require.Equal(c.t, strconv.Itoa(c.nextID), string(*msg.ID))
+
+	if msg.Error != nil {
+		return msg.Error
+	}
+
+	if result != nil {
+		require.NoError(c.t, json.Unmarshal(msg.Result, result))
+	}
+
+	return nil
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+
+	require.NoError(c.t, c.conn.notify(method, params))
+}
+
+func (c *client) diagnostics() publishDiagnosticsParams {
+	c.t.Helper()
+
+	msg := c.next()
+	require.Equal(c.t, methodPublishDiagnostics, msg.Method)
+
+	var params publishDiagnosticsParams
+	require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+
+	return params
+}
+
+func (c *client) initialize(params any) {
+	c.t.Helper()
+
+	var result initializeResult
+	require.Nil(c.t, c.call(methodInitialize, params, &result))
+	assert.Equal(c.t, textDocumentSyncKindFull, result.Capabilities.TextDocumentSync.Change)
+	assert.Equal(c.t, []string{codeActionKindQuickFix, codeActionKindSourceFixAll}, result.Capabilities.CodeActionProvider.CodeActionKinds)
+
+	c.notify(methodInitialized, struct{}{})
+}
+
+func (c *client) exit() {
+	c.t.Helper()
+
+	require.Nil(c.t, c.call(methodShutdown, nil, nil))
+	c.notify(methodExit, nil)
+
+	select {
+	case err := <-c.done:
+		require.NoError(c.t, err)
+	case <-time.After(10 * time.Second):
+		c.t.Fatal("server didn't exit")
+	}
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	uri := fileURI(filepath.Join(dir, "main.go"))
+
+	c := newClient(t, nil)
+	c.initialize(struct{}{})
+
+	c.notify(methodDidOpen, didOpenTextDocumentParams{
+		TextDocument: textDocumentItem{URI: uri, Version: 1, Text: source},
+	})
+
+	diags := c.diagnostics()
+	assert.Equal(t, uri, diags.URI)
+	assert.Equal(t, 1, diags.Version)
+
+	require.Len(t, diags.Diagnostics, 3)
+
+	first := diags.Diagnostics[0]
+	assert.Equal(t, lspRange{Start: position{9, 1}, End: position{9, 1}}, first.Range)
+	assert.Equal(t, diagnosticSeverityError, first.Severity)
+	assert.Equal(t, "no-intersection", first.Code)
+	assert.Equal(t, "wsl", first.Source)
+	assert.Equal(t, "missing whitespace above this line (no shared variables above if)", first.Message)
+	assert.Contains(t, first.CodeDescription.Href, "CHECKS.md#if")
+
+	t.Run("quick fix", func(t *testing.T) {
+		var actions []codeAction
+		require.Nil(t, c.call(methodCodeAction, codeActionParams{
+			TextDocument: textDocumentIdentifier{URI: uri},
+			Range:        first.Range,
+			Context:      codeActionContext{Only: []string{codeActionKindQuickFix}},
+		}, &actions))
+
+		require.Len(t, actions, 1)
+		assert.Equal(t, codeActionKindQuickFix, actions[0].Kind)
+		assert.Equal(t, []textEdit{{
+			Range:   lspRange{Start: position{9, 0}, End: position{9, 0}},
+			NewText: "\n",
+		}}, actions[0].Edit.Changes[uri])
+	})
+
+	t.Run("fix all", func(t *testing.T) {
+		var actions []codeAction
+		require.Nil(t, c.call(methodCodeAction, codeActionParams{
+			TextDocument: textDocumentIdentifier{URI: uri},
+			Context:      codeActionContext{Only: []string{"source"}},
+		}, &actions))
+
+		require.Len(t, actions, 1)
+		assert.Equal(t, fixAllTitle, actions[0].Title)
+		assert.Equal(t, codeActionKindSourceFixAll, actions[0].Kind)
+		assert.Equal(t, []textEdit{{
+			Range:   lspRange{Start: position{0, 0}, End: position{20, 0}},
+			NewText: formatted,
+		}}, actions[0].Edit.Changes[uri])
+	})
+
+	t.Run("change", func(t *testing.T) {
+		c.notify(methodDidChange, didChangeTextDocumentParams{
+			TextDocument:   versionedTextDocumentIdentifier{URI: uri, Version: 2},
+			ContentChanges: []textDocumentContentChangeEvent{{Text: formatted}},
+		})
+
+		diags := c.diagnostics()
+		assert.Equal(t, 2, diags.Version)
+		assert.Empty(t, diags.Diagnostics)
+
+		var actions []codeAction
+		require.Nil(t, c.call(methodCodeAction, codeActionParams{
+			TextDocument: textDocumentIdentifier{URI: uri},
+		}, &actions))
+		assert.Empty(t, actions)
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		c.notify(methodDidChange, didChangeTextDocumentParams{
+			TextDocument:   versionedTextDocumentIdentifier{URI: uri, Version: 3},
+			ContentChanges: []textDocumentContentChangeEvent{{Text: "package"}},
+		})
+
+		assert.Empty(t, c.diagnostics().Diagnostics)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		c.notify(methodDidClose, didCloseTextDocumentParams{
+			TextDocument: textDocumentIdentifier{URI: uri},
+		})
+
+		assert.Empty(t, c.diagnostics().Diagnostics)
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		err := c.call("textDocument/hover", struct{}{}, nil)
+		require.NotNil(t, err)
+		assert.Equal(t, codeMethodNotFound, err.Code)
+	})
+
+	c.exit()
+}
+
+func TestServerConfigReload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	uri := fileURI(filepath.Join(dir, "main.go"))
+	configPath := filepath.Join(dir, ".wsl.yml")
+
+	c := newClient(t, nil)
+
+	var params initializeParams
+
+	params.Capabilities.Workspace.DidChangeWatchedFiles.DynamicRegistration = true
+
+	c.nextID++
+	require.NoError(t, c.conn.request(c.nextID, methodInitialize, params))
+
+	// The server registers a watcher for the configuration files before
+	// responding.
+	register := c.next()
+	require.Equal(t, methodRegisterCapability, register.Method)
+
+	var registrations registrationParams
+	require.NoError(t, json.Unmarshal(register.Params, &registrations))
+	require.Len(t, registrations.Registrations, 1)
+	assert.Equal(t, methodDidChangeWatchedFiles, registrations.Registrations[0].Method)
+	require.NoError(t, c.conn.reply(register.ID, nil, nil))
+
+	response := c.next()
+	require.Nil(t, response.Error)
+
+	c.notify(methodDidOpen, didOpenTextDocumentParams{
+		TextDocument: textDocumentItem{URI: uri, Version: 1, Text: source},
+	})
+	require.Len(t, c.diagnostics().Diagnostics, 3)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("default: none\nenable: [if]\nseverity:\n  if: warning\n"), 0o600))
+	c.notify(methodDidChangeWatchedFiles, didChangeWatchedFilesParams{
+		Changes: []fileEvent{{URI: fileURI(configPath), Type: 1}},
+	})
+
+	diags := c.diagnostics().Diagnostics
+	require.Len(t, diags, 1)
+	assert.Equal(t, diagnosticSeverityWarning, diags[0].Severity)
+
+	// Invalid configuration files are reported in the document.
+	require.NoError(t, os.WriteFile(configPath, []byte("enable: [iff]\n"), 0o600))
+	c.notify(methodDidSave, didSaveTextDocumentParams{
+		TextDocument: textDocumentIdentifier{URI: fileURI(configPath)},
+	})
+
+	diags = c.diagnostics().Diagnostics
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "key 'enable': invalid check 'iff'")
+
+	c.exit()
+}
+
+func TestOffsetToPosition(t *testing.T) {
+	t.Parallel()
+
+	text := []byte("a\nå😀b\n")
+
+	assert.Equal(t, position{0, 0}, offsetToPosition(text, 0))
+	assert.Equal(t, position{1, 0}, offsetToPosition(text, 2))
+	assert.Equal(t, position{1, 1}, offsetToPosition(text, 4))
+	assert.Equal(t, position{1, 3}, offsetToPosition(text, 8))
+	assert.Equal(t, position{2, 0}, offsetToPosition(text, len(text)))
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// baselineVersion is the version of the baseline file format.
+const baselineVersion = 1
+
+// baseline holds issues that should be suppressed. Issues are matched by file,
+// enclosing function and a fingerprint of the source so the baseline is still
+// valid when lines are added or removed elsewhere in the file.
+type baseline struct {
+	Version int             `json:"version"`
+	Entries []baselineEntry `json:"entries"`
+}
+
+type baselineEntry struct {
+	File        string `json:"file"`
+	Function    string `json:"function,omitempty"`
+	Check       string `json:"check"`
+	Fingerprint string `json:"fingerprint"`
+	Message     string `json:"message"`
+	// Count is the number of identical issues in the function.
+	Count int `json:"count"`
+}
+
+func (e *baselineEntry) key() string {
+	return e.File + "\x00" + e.Function + "\x00" + e.Check + "\x00" + e.Fingerprint
+}
+
+// newBaseline creates a baseline with all issues in the report.
+func newBaseline(r *report) *baseline {
+	wd, _ := os.Getwd()
+	sources := sourceCache{}
+	entries := map[string]*baselineEntry{}
+
+	for _, i := range r.Issues {
+		entry := newBaselineEntry(wd, sources, i)
+
+		if existing, ok := entries[entry.key()]; ok {
+			existing.Count++
+			continue
+		}
+
+		entries[entry.key()] = &entry
+	}
+
+	b := &baseline{Version: baselineVersion, Entries: []baselineEntry{}}
+	for _, entry := range entries {
+		b.Entries = append(b.Entries, *entry)
+	}
+
+	slices.SortFunc(b.Entries, func(a, b baselineEntry) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Function, b.Function),
+			cmp.Compare(a.Check, b.Check),
+			cmp.Compare(a.Message, b.Message),
+			cmp.Compare(a.Fingerprint, b.Fingerprint),
+		)
+	})
+
+	return b
+}
+
+func newBaselineEntry(wd string, sources sourceCache, i issue) baselineEntry {
+	filename, _ := relativePath(wd, i.Position.Filename)
+
+	return baselineEntry{
+		File:        filepath.ToSlash(filename),
+		Function:    i.Function,
+		Check:       i.Check.String(),
+		Fingerprint: fingerprint(sources.get(i.Position.Filename), i),
+		Message:     i.Message,
+		Count:       1,
+	}
+}
+
+// fingerprint returns a hash of the check, message and the content of the line
+// the issue is reported on. Since a lot of issues are reported on empty lines,
+// the first non-empty line from the issue is used.
+func fingerprint(src []byte, i issue) string {
+	var line []byte
+
+	if i.Position.Offset >= 0 && i.Position.Offset <= len(src) {
+		// Start from the beginning of the line.
+		rest := src[i.Position.Offset-min(i.Position.Offset, i.Position.Column-1):]
+
+		for len(rest) > 0 && len(line) == 0 {
+			line, rest, _ = bytes.Cut(rest, []byte("\n"))
+			line = bytes.TrimSpace(line)
+		}
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", i.Check, i.Message, line)
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func readBaseline(filename string) (*baseline, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var b baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", filename, err)
+	}
+
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", b.Version, filename)
+	}
+
+	return &b, nil
+}
+
+func (b *baseline) write(filename string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // The baseline is committed and shared, not a secret.
+	if err := os.WriteFile(filename, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+
+	return nil
+}
+
+// apply removes all issues in the baseline from the report and returns the
+// entries no longer reported with Count set to the number of issues not found.
+// Entries for files that wasn't analyzed are never stale.
+func (b *baseline) apply(r *report) []baselineEntry {
+	wd, _ := os.Getwd()
+	sources := sourceCache{}
+	remaining := map[string]int{}
+
+	for _, entry := range b.Entries {
+		remaining[entry.key()] += entry.Count
+	}
+
+	issues := r.Issues[:0]
+
+	for _, i := range r.Issues {
+		entry := newBaselineEntry(wd, sources, i)
+
+		if remaining[entry.key()] > 0 {
+			remaining[entry.key()]--
+			continue
+		}
+
+		issues = append(issues, i)
+	}
+
+	r.Issues = issues
+
+	analyzed := map[string]struct{}{}
+
+	for filename := range r.Files {
+		rel, _ := relativePath(wd, filename)
+		analyzed[filepath.ToSlash(rel)] = struct{}{}
+	}
+
+	var stale []baselineEntry
+
+	for _, entry := range b.Entries {
+		if _, ok := analyzed[entry.File]; !ok {
+			continue
+		}
+
+		if n := remaining[entry.key()]; n > 0 {
+			entry.Count = n
+			stale = append(stale, entry)
+
+			// Duplicate entries share the remaining count.
+			remaining[entry.key()] = 0
+		}
+	}
+
+	return stale
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baselineSource = `package main
+
+import "fmt"
+
+func main() {
+	a := 1
+	if true {
+		fmt.Println("x")
+	}
+	b := 1
+	fmt.Println(a, b)
+}
+
+type T struct{}
+
+func (*T) Method() {
+	a := 1
+	if true {
+		fmt.Println("x")
+	}
+	b := 1
+	if true {
+		fmt.Println("x")
+	}
+
+	fmt.Println(a, b)
+}
+`
+
+func TestBaseline(t *testing.T) {
+	dir := newModule(t, baselineSource)
+	baselineFile := filepath.Join(dir, "wsl-baseline.json")
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runReport([]string{"-write-baseline", baselineFile, "./..."}, &stdout, &stderr)
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.Equal(t, "wrote 5 issues to "+baselineFile+"\n", stderr.String())
+	assert.Empty(t, stdout.String())
+
+	b, err := readBaseline(baselineFile)
+	require.NoError(t, err)
+
+	// The two identical issues in Method is a single entry.
+	require.Len(t, b.Entries, 4)
+
+	for n, expected := range []baselineEntry{
+		{File: "main.go", Function: "T.Method", Check: "assign", Count: 1},
+		{File: "main.go", Function: "T.Method", Check: "if", Count: 2},
+		{File: "main.go", Function: "main", Check: "assign", Count: 1},
+		{File: "main.go", Function: "main", Check: "if", Count: 1},
+	} {
+		assert.Equal(t, expected.File, b.Entries[n].File)
+		assert.Equal(t, expected.Function, b.Entries[n].Function)
+		assert.Equal(t, expected.Check, b.Entries[n].Check)
+		assert.Equal(t, expected.Count, b.Entries[n].Count)
+	}
+
+	t.Run("all issues suppressed", func(t *testing.T) {
+		stdout.Reset()
+		stderr.Reset()
+
+		exitCode := runReport([]string{"-baseline", baselineFile, "./..."}, &stdout, &stderr)
+		require.Equal(t, 0, exitCode, stderr.String())
+		assert.Empty(t, stdout.String())
+		assert.Empty(t, stderr.String())
+	})
+
+	t.Run("new issues reported and shifted lines ignored", func(t *testing.T) {
+		src := strings.Replace(baselineSource, "func main() {", "func added() {\n\tc := 1\n\tif true {\n\t\tfmt.Println(\"x\")\n\t}\n\n\t_ = c\n}\n\nfunc main() {", 1)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o600))
+
+		stdout.Reset()
+		stderr.Reset()
+
+		exitCode := runReport([]string{"-baseline", baselineFile, "./..."}, &stdout, &stderr)
+		require.Equal(t, 3, exitCode, stderr.String())
+		assert.Equal(t, filepath.Join(dir, "main.go")+":7:2: missing whitespace above this line (no shared variables above if)\n", stdout.String())
+		assert.Empty(t, stderr.String())
+	})
+
+	t.Run("stale entries", func(t *testing.T) {
+		src := strings.Replace(baselineSource, "\tb := 1\n\tif true {", "\tb := 1\n\n\tif true {", 1)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o600))
+
+		stdout.Reset()
+		stderr.Reset()
+
+		exitCode := runReport([]string{"-baseline", baselineFile, "-format", "checkstyle", "./..."}, &stdout, &stderr)
+		require.Equal(t, 0, exitCode, stderr.String())
+		assert.NotContains(t, stdout.String(), "<error")
+		assert.Equal(t, "main.go: stale baseline entry for T.Method, 1 issue no longer reported: missing whitespace above this line (no shared variables above if)\n", stderr.String())
+	})
+
+	t.Run("invalid baseline", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(baselineFile, []byte(`{"version": 2}`), 0o600))
+
+		stderr.Reset()
+
+		exitCode := runReport([]string{"-baseline", baselineFile, "./..."}, &stdout, &stderr)
+		require.Equal(t, 1, exitCode)
+		assert.Contains(t, stderr.String(), "unsupported baseline version 2")
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("package main\n\nfunc f() {\n\n\tif true {\n\t}\n}\n")
+	moved := []byte("package main\n\n// Comment\nfunc f() {\n\n\tif true {\n\t}\n}\n")
+	changed := []byte("package main\n\n// Comment\nfunc f() {\n\n\tif !true {\n\t}\n}\n")
+
+	onEmptyLine := issue{Message: "message", Position: token.Position{Offset: 25, Line: 4, Column: 1}}
+	onIf := issue{Message: "message", Position: token.Position{Offset: 27, Line: 5, Column: 2}}
+	onMovedEmptyLine := issue{Message: "message", Position: token.Position{Offset: 36, Line: 5, Column: 1}}
+
+	// The first non-empty line is used for issues on empty lines.
+	assert.Equal(t, fingerprint(src, onIf), fingerprint(src, onEmptyLine))
+	assert.Equal(t, fingerprint(src, onEmptyLine), fingerprint(moved, onMovedEmptyLine))
+	assert.NotEqual(t, fingerprint(src, onEmptyLine), fingerprint(changed, onMovedEmptyLine))
+
+	otherMessage := onIf
+	otherMessage.Message = "other"
+
+	assert.NotEqual(t, fingerprint(src, onIf), fingerprint(src, otherMessage))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+
+	"github.com/bombsimon/wsl/v5"
+)
+
+type checkstyleOutput struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// writeCheckstyle writes the issues in the Checkstyle XML format grouped by
+// file. The source of each error is `wsl.<check>`.
+func writeCheckstyle(w io.Writer, r *report) error {
+	wd, _ := os.Getwd()
+
+	output := checkstyleOutput{Version: "5.0"}
+
+	for _, i := range r.Issues {
+		name, _ := relativePath(wd, i.Position.Filename)
+
+		// Issues are sorted by filename so all issues in the same file are
+		// next to each other.
+		if len(output.Files) == 0 || output.Files[len(output.Files)-1].Name != name {
+			output.Files = append(output.Files, checkstyleFile{Name: name})
+		}
+
+		file := &output.Files[len(output.Files)-1]
+		file.Errors = append(file.Errors, checkstyleError{
+			Line:     i.Position.Line,
+			Column:   i.Position.Column,
+			Severity: checkstyleSeverity(i.Severity),
+			Message:  i.Message,
+			Source:   "wsl." + i.Check.String(),
+		})
+	}
+
+	return writeXML(w, output)
+}
+
+func checkstyleSeverity(severity wsl.Severity) string {
+	switch severity {
+	case wsl.SeverityWarning:
+		return "warning"
+	case wsl.SeverityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// writeXML writes v as indented XML with a header.
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// unifiedDiff returns a unified diff between a and b, or an empty string if
+// they're equal.
+func unifiedDiff(filename string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "--- %s.orig\n+++ %s\n", filename, filename)
+
+	for _, h := range hunks(lines) {
+		sb.WriteString(h)
+	}
+
+	return sb.String()
+}
+
+func splitLines(src []byte) []string {
+	if len(src) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(src), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines computes the shortest edit script between a and b with the linear
+// space variant of the Myers algorithm. The middle snake of the edit script is
+// found by searching from both ends at the same time and the parts before and
+// after it are diffed recursively, so only O(N+M) memory is used.
+func diffLines(a, b []string) []diffLine {
+	size := 2*((len(a)+len(b)+1)/2) + 2
+
+	d := &differ{
+		a:  a,
+		b:  b,
+		v1: make([]int, size),
+		v2: make([]int, size),
+	}
+
+	d.compare(0, len(a), 0, len(b))
+
+	return d.lines
+}
+
+// differ holds the state while computing the diff. The vectors holding the
+// furthest reaching paths are shared between all calls to middleSnake since
+// they're not needed when recursing.
+type differ struct {
+	a, b   []string
+	v1, v2 []int
+	lines  []diffLine
+}
+
+// compare adds the lines for the shortest edit script between a[aLo:aHi] and
+// b[bLo:bHi].
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.lines = append(d.lines, diffLine{diffEqual, d.a[aLo]})
+		aLo++
+		bLo++
+	}
+
+	suffix := aHi
+
+	for aHi > aLo && bHi > bLo && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+
+	switch {
+	case aLo == aHi:
+		d.insert(bLo, bHi)
+	case bLo == bHi:
+		d.delete(aLo, aHi)
+	default:
+		x, y, ok := d.middleSnake(aLo, aHi, bLo, bHi)
+		if !ok {
+			d.delete(aLo, aHi)
+			d.insert(bLo, bHi)
+
+			break
+		}
+
+		d.compare(aLo, x, bLo, y)
+		d.compare(x, aHi, y, bHi)
+	}
+
+	for _, line := range d.a[aHi:suffix] {
+		d.lines = append(d.lines, diffLine{diffEqual, line})
+	}
+}
+
+func (d *differ) delete(lo, hi int) {
+	for _, line := range d.a[lo:hi] {
+		d.lines = append(d.lines, diffLine{diffDelete, line})
+	}
+}
+
+func (d *differ) insert(lo, hi int) {
+	for _, line := range d.b[lo:hi] {
+		d.lines = append(d.lines, diffLine{diffInsert, line})
+	}
+}
+
+// middleSnake returns the point where the furthest reaching paths from the
+// start and the end of a[aLo:aHi] and b[bLo:bHi] overlap, which is on a
+// shortest edit script. If the paths never overlap, there are no common lines
+// and ok is false.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	var (
+		a, b   = d.a[aLo:aHi], d.b[bLo:bHi]
+		n, m   = len(a), len(b)
+		maxD   = (n + m + 1) / 2
+		offset = maxD
+		size   = 2*maxD + 2
+		v1     = d.v1[:size]
+		v2     = d.v2[:size]
+	)
+
+	for i := range size {
+		v1[i], v2[i] = -1, -1
+	}
+
+	v1[offset+1], v2[offset+1] = 0, 0
+
+	// If the difference in length is odd, the paths can only overlap when
+	// searching forward and if it's even only when searching backward.
+	delta := n - m
+	front := delta%2 != 0
+
+	// Diagonals to skip since their paths went past the end of a or b.
+	var k1Start, k1End, k2Start, k2End int
+
+	for step := range maxD {
+		for k1 := -step + k1Start; k1 <= step-k1End; k1 += 2 {
+			k1Offset := offset + k1
+
+			var x1 int
+			if k1 == -step || (k1 != step && v1[k1Offset-1] < v1[k1Offset+1]) {
+				x1 = v1[k1Offset+1]
+			} else {
+				x1 = v1[k1Offset-1] + 1
+			}
+
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+
+			v1[k1Offset] = x1
+
+			switch {
+			case x1 > n:
+				k1End += 2
+			case y1 > m:
+				k1Start += 2
+			case front:
+				k2Offset := offset + delta - k1
+				if k2Offset >= 0 && k2Offset < size && v2[k2Offset] != -1 && x1 >= n-v2[k2Offset] {
+					return aLo + x1, bLo + y1, true
+				}
+			}
+		}
+
+		for k2 := -step + k2Start; k2 <= step-k2End; k2 += 2 {
+			k2Offset := offset + k2
+
+			var x2 int
+			if k2 == -step || (k2 != step && v2[k2Offset-1] < v2[k2Offset+1]) {
+				x2 = v2[k2Offset+1]
+			} else {
+				x2 = v2[k2Offset-1] + 1
+			}
+
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+
+			v2[k2Offset] = x2
+
+			switch {
+			case x2 > n:
+				k2End += 2
+			case y2 > m:
+				k2Start += 2
+			case !front:
+				k1Offset := offset + delta - k2
+				if k1Offset >= 0 && k1Offset < size && v1[k1Offset] != -1 {
+					x1 := v1[k1Offset]
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + x1 - (k1Offset - offset), true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// hunks groups the lines into hunks with diffContext lines of context.
+func hunks(lines []diffLine) []string {
+	var (
+		result     []string
+		start      = -1
+		lastChange = -1
+	)
+
+	flush := func(end int) {
+		from := max(start-diffContext, 0)
+		to := min(end+diffContext+1, len(lines))
+
+		// Line numbers before the hunk.
+		aLine, bLine := 1, 1
+
+		for _, l := range lines[:from] {
+			if l.op != diffInsert {
+				aLine++
+			}
+
+			if l.op != diffDelete {
+				bLine++
+			}
+		}
+
+		var (
+			body       strings.Builder
+			aLen, bLen int
+		)
+
+		for _, l := range lines[from:to] {
+			switch l.op {
+			case diffEqual:
+				body.WriteString(" ")
+
+				aLen++
+				bLen++
+			case diffDelete:
+				body.WriteString("-")
+
+				aLen++
+			case diffInsert:
+				body.WriteString("+")
+
+				bLen++
+			}
+
+			body.WriteString(l.text)
+
+			if !strings.HasSuffix(l.text, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		result = append(result, fmt.Sprintf("@@ -%s +%s @@\n%s", hunkRange(aLine, aLen), hunkRange(bLine, bLen), body.String()))
+	}
+
+	for i, l := range lines {
+		if l.op == diffEqual {
+			continue
+		}
+
+		if start >= 0 && i-lastChange > 2*diffContext {
+			flush(lastChange)
+
+			start = -1
+		}
+
+		if start < 0 {
+			start = i
+		}
+
+		lastChange = i
+	}
+
+	if start >= 0 {
+		flush(lastChange)
+	}
+
+	return result
+}
+
+func hunkRange(line, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", line-1)
+	}
+
+	if length == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+
+	return fmt.Sprintf("%d,%d", line, length)
+}
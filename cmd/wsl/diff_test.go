@@ -0,0 +1,198 @@
+package main
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	lines := func(from, to int) string {
+		var sb strings.Builder
+		for i := from; i <= to; i++ {
+			sb.WriteString(strings.Repeat("x", i) + "\n")
+		}
+
+		return sb.String()
+	}
+
+	for _, tc := range []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{
+			name: "equal",
+			a:    lines(1, 5),
+			b:    lines(1, 5),
+		},
+		{
+			name: "multiple hunks",
+			a:    lines(1, 20),
+			b:    lines(1, 2) + "\n" + lines(3, 17) + lines(19, 20),
+			expected: `--- a.go.orig
++++ a.go
+@@ -1,5 +1,6 @@
+ x
+ xx
++
+ xxx
+ xxxx
+ xxxxx
+@@ -15,6 +16,5 @@
+ xxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxx
+-xxxxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxxxxx
+`,
+		},
+		{
+			name: "hunks at start and end",
+			a:    "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n",
+			b:    "a\n\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n",
+			expected: `--- a.go.orig
++++ a.go
+@@ -1,4 +1,5 @@
+ a
++
+ b
+ c
+ d
+@@ -9,4 +10,3 @@
+ i
+ j
+ k
+-l
+`,
+		},
+		{
+			name: "close changes in one hunk",
+			a:    lines(1, 8),
+			b:    lines(1, 2) + "\n" + lines(3, 6) + lines(8, 8),
+			expected: `--- a.go.orig
++++ a.go
+@@ -1,8 +1,8 @@
+ x
+ xx
++
+ xxx
+ xxxx
+ xxxxx
+ xxxxxx
+-xxxxxxx
+ xxxxxxxx
+`,
+		},
+		{
+			name: "no trailing newline",
+			a:    "a\nb",
+			b:    "a\n\nb",
+			expected: `--- a.go.orig
++++ a.go
+@@ -1,2 +1,3 @@
+ a
++
+ b
+\ No newline at end of file
+`,
+		},
+		{
+			name: "trailing newline added",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			expected: `--- a.go.orig
++++ a.go
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+		{
+			name: "from empty",
+			b:    "a\n",
+			expected: `--- a.go.orig
++++ a.go
+@@ -0,0 +1 @@
++a
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, unifiedDiff("a.go", []byte(tc.a), []byte(tc.b)))
+		})
+	}
+}
+
+func TestDiffLinesShortest(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewPCG(1, 2))
+
+	randomLines := func() []string {
+		var lines []string
+		for range rnd.IntN(30) {
+			lines = append(lines, string(rune('a'+rnd.IntN(4))))
+		}
+
+		return lines
+	}
+
+	for range 500 {
+		a, b := randomLines(), randomLines()
+		lines := diffLines(a, b)
+
+		var gotA, gotB []string
+
+		changes := 0
+
+		for _, l := range lines {
+			if l.op != diffInsert {
+				gotA = append(gotA, l.text)
+			}
+
+			if l.op != diffDelete {
+				gotB = append(gotB, l.text)
+			}
+
+			if l.op != diffEqual {
+				changes++
+			}
+		}
+
+		require.Equal(t, a, gotA)
+		require.Equal(t, b, gotB)
+		require.Equal(t, len(a)+len(b)-2*lcsLength(a, b), changes, "a: %v, b: %v", a, b)
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+
+	for i := range a {
+		cur := make([]int, len(b)+1)
+
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bombsimon/wsl/v5"
+)
+
+const stdinFilename = "<standard input>"
+
+type formatter struct {
+	list       bool
+	diff       bool
+	write      bool
+	syntaxOnly bool
+	configFile string
+
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// runFmt runs the `fmt` subcommand and returns the exit code. Like gofmt, the
+// formatted source is written to stdout unless -l, -d or -w is used and if no
+// paths are given the source is read from stdin.
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	f := &formatter{stdout: stdout, stderr: stderr}
+
+	flags := flag.NewFlagSet("wsl fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.BoolVar(&f.list, "l", false, "list files whose formatting differs from wsl's")
+	flags.BoolVar(&f.diff, "d", false, "display diffs instead of rewriting files")
+	flags.BoolVar(&f.write, "w", false, "write result to (source) file instead of stdout")
+	flags.BoolVar(&f.syntaxOnly, "syntax-only", false, "don't type check files, use heuristics based on names instead")
+	flags.StringVar(&f.configFile, "config", "", "path to configuration file, if not set "+strings.Join(wsl.ConfigFileNames, ", ")+" is searched for from the file directory and up")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: wsl fmt [flags] [path ...]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if f.write {
+			fmt.Fprintln(stderr, "error: cannot use -w with standard input")
+			return 2
+		}
+
+		src, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+
+		if err := f.processSource(stdinFilename, src); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+
+		return 0
+	}
+
+	exitCode := 0
+
+	for _, arg := range flags.Args() {
+		if err := f.processPath(arg); err != nil {
+			fmt.Fprintln(stderr, err)
+
+			exitCode = 2
+		}
+	}
+
+	return exitCode
+}
+
+// processPath formats a file or all Go files in a directory. A path ending
+// with `/...` is handled the same as the directory.
+func (f *formatter) processPath(path string) error {
+	path = strings.TrimSuffix(path, "...")
+	if path == "" {
+		path = "."
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return f.processFile(path)
+	}
+
+	var errs []error
+
+	err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !isGoFile(d.Name()) {
+			return nil
+		}
+
+		if err := f.processFile(path); err != nil {
+			errs = append(errs, err)
+		}
+
+		return nil
+	})
+
+	return errors.Join(append(errs, err)...)
+}
+
+func isGoFile(name string) bool {
+	return strings.HasSuffix(name, ".go") && !strings.HasPrefix(name, ".")
+}
+
+func (f *formatter) processFile(filename string) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return f.processSource(filename, src)
+}
+
+func (f *formatter) processSource(filename string, src []byte) error {
+	cfg, err := f.config(filename)
+	if err != nil {
+		return err
+	}
+
+	if f.syntaxOnly {
+		cfg.SyntaxOnly = true
+	}
+
+	res, err := wsl.FormatFile(filename, src, cfg)
+	if err != nil {
+		return err
+	}
+
+	changed := !bytes.Equal(src, res)
+
+	if f.list && changed {
+		fmt.Fprintln(f.stdout, filename)
+	}
+
+	if f.diff && changed {
+		fmt.Fprint(f.stdout, unifiedDiff(filename, src, res))
+	}
+
+	if f.write && changed {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filename, res, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	if !f.list && !f.diff && !f.write {
+		_, err := f.stdout.Write(res)
+		return err
+	}
+
+	return nil
+}
+
+// config returns the configuration for the file from the configuration file
+// passed with -config or found from the directory of the file.
+func (f *formatter) config(filename string) (*wsl.Configuration, error) {
+	cfg := wsl.NewConfig()
+
+	path := f.configFile
+	if path == "" {
+		dir := "."
+		if filename != stdinFilename {
+			dir = filepath.Dir(filename)
+		}
+
+		var err error
+
+		path, err = wsl.FindConfigFile(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if path == "" {
+		return cfg, nil
+	}
+
+	cf, err := wsl.LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cf.ApplyFor(cfg, filename); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
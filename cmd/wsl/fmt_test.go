@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unformatted = `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	a := 1
+	if true {
+		fmt.Println("cuddled")
+	}
+	err := errors.New("x")
+
+	if err != nil {
+		panic(err)
+	}
+	for i := range a {
+
+		fmt.Println(i)
+	}
+}
+`
+
+const formatted = `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	a := 1
+
+	if true {
+		fmt.Println("cuddled")
+	}
+
+	err := errors.New("x")
+	if err != nil {
+		panic(err)
+	}
+
+	for i := range a {
+		fmt.Println(i)
+	}
+}
+`
+
+func TestFmtStdin(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+
+	code := runFmt(nil, strings.NewReader(unformatted), &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, formatted, stdout.String())
+}
+
+func TestFmtFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	unformattedPath := filepath.Join(dir, "a.go")
+	formattedPath := filepath.Join(dir, "sub", "b.go")
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(formattedPath), 0o750))
+	require.NoError(t, os.WriteFile(unformattedPath, []byte(unformatted), 0o600))
+	require.NoError(t, os.WriteFile(formattedPath, []byte(formatted), 0o600))
+
+	t.Run("list", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := runFmt([]string{"-l", dir + "/..."}, nil, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, unformattedPath+"\n", stdout.String())
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := runFmt([]string{"-d", unformattedPath, formattedPath}, nil, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+
+		out := stdout.String()
+		assert.True(t, strings.HasPrefix(out, "--- "+unformattedPath+".orig\n+++ "+unformattedPath+"\n@@ "), out)
+		assert.Contains(t, out, "\n \ta := 1\n+\n \tif true {\n")
+		assert.NotContains(t, out, formattedPath)
+	})
+
+	t.Run("write", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := runFmt([]string{"-w", dir}, nil, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+		assert.Empty(t, stdout.String())
+
+		content, err := os.ReadFile(unformattedPath)
+		require.NoError(t, err)
+		assert.Equal(t, formatted, string(content))
+	})
+}
+
+func TestFmtConfigFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+
+	require.NoError(t, os.WriteFile(path, []byte(unformatted), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".wsl.yml"), []byte("default: none\nenable: [leading-whitespace]\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+
+	code := runFmt([]string{path}, nil, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, strings.Replace(unformatted, "range a {\n\n", "range a {\n", 1), stdout.String())
+}
+
+func TestFmtErrorFromOtherFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+
+	// The file is type checked on its own so the type of `err` is invalid
+	// since `do` is declared in another file.
+	require.NoError(t, os.WriteFile(path, []byte("package a\n\nfunc f() {\n\terr := do()\n\n\tif err != nil {\n\t\tpanic(err)\n\t}\n}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.go"), []byte("package a\n\nfunc do() error { return nil }\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".wsl.yml"), []byte("default: none\nenable: [err]\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+
+	code := runFmt([]string{"-l", path}, nil, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, path+"\n", stdout.String())
+}
+
+func TestFmtErrors(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, 2, runFmt([]string{"-w"}, strings.NewReader(unformatted), &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "cannot use -w with standard input")
+
+	stderr.Reset()
+
+	assert.Equal(t, 2, runFmt(nil, strings.NewReader("package"), &stdout, &stderr))
+	assert.NotEmpty(t, stderr.String())
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bombsimon/wsl/v5"
+)
+
+// junitPassedTestCase is the name of the test case added to packages without
+// any issues so each package is represented in the report.
+const junitPassedTestCase = "wsl"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",cdata"`
+}
+
+// writeJUnit writes the issues in the JUnit XML format with one test suite per
+// package and one failing test case per check reported in the package.
+// Packages without issues get a single passing test case.
+func writeJUnit(w io.Writer, r *report) error {
+	wd, _ := os.Getwd()
+
+	byPackage := map[string]map[wsl.CheckType][]issue{}
+
+	for _, i := range r.Issues {
+		if byPackage[i.Package] == nil {
+			byPackage[i.Package] = map[wsl.CheckType][]issue{}
+		}
+
+		byPackage[i.Package][i.Check] = append(byPackage[i.Package][i.Check], i)
+	}
+
+	output := junitTestSuites{Name: "wsl"}
+
+	for _, pkg := range r.Packages {
+		suite := junitTestSuite{Name: pkg}
+		checks := byPackage[pkg]
+
+		if len(checks) == 0 {
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      junitPassedTestCase,
+				ClassName: pkg,
+			})
+		}
+
+		for check := range wsl.CheckTypes() {
+			issues, ok := checks[check]
+			if !ok {
+				continue
+			}
+
+			var body strings.Builder
+
+			for _, i := range issues {
+				filename, _ := relativePath(wd, i.Position.Filename)
+				fmt.Fprintf(&body, "%s:%d:%d: %s\n", filename, i.Position.Line, i.Position.Column, i.Message)
+			}
+
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      check.String(),
+				ClassName: pkg,
+				Failure: &junitFailure{
+					Message: fmt.Sprintf("%d %s", len(issues), pluralize("issue", len(issues))),
+					Type:    check.String(),
+					Body:    body.String(),
+				},
+			})
+
+			suite.Failures++
+		}
+
+		suite.Tests = len(suite.TestCases)
+
+		output.Tests += suite.Tests
+		output.Failures += suite.Failures
+		output.Suites = append(output.Suites, suite)
+	}
+
+	return writeXML(w, output)
+}
+
+func pluralize(word string, n int) string {
+	if n == 1 {
+		return word
+	}
+
+	return word + "s"
+}
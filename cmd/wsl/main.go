@@ -1,10 +1,20 @@
 package main
 
 import (
+	"os"
+
 	"github.com/bombsimon/wsl/v5"
 	"golang.org/x/tools/go/analysis/singlechecker"
 )
 
 func main() {
-	singlechecker.Main(wsl.NewAnalyzer(nil))
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		os.Exit(runFmt(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
+	if useSinglechecker(os.Args[1:]) {
+		singlechecker.Main(wsl.NewAnalyzer(nil))
+	}
+
+	os.Exit(runReport(os.Args[1:], os.Stdout, os.Stderr))
 }
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bombsimon/wsl/v5"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// reporters holds all supported output formats for -format.
+var reporters = map[string]func(w io.Writer, r *report) error{
+	"checkstyle": writeCheckstyle,
+	"junit":      writeJUnit,
+	"sarif":      writeSARIF,
+	"text":       writeText,
+}
+
+// report is the result of analyzing all packages matching the patterns.
+type report struct {
+	// Packages holds the path of all analyzed packages, sorted.
+	Packages []string
+	// Files holds the name of all analyzed files.
+	Files  map[string]struct{}
+	Issues []issue
+}
+
+// issue is a diagnostic reported by the analyzer with the check and severity
+// recovered and positions resolved.
+type issue struct {
+	Package string
+	// Function is the name of the function declaration enclosing the issue,
+	// empty if the issue isn't within a function.
+	Function string
+	Position token.Position
+	Check    wsl.CheckType
+	Reason   wsl.Reason
+	Severity wsl.Severity
+	Message  string
+	Edits    []edit
+}
+
+// edit is a suggested fix replacing the source between Start and End.
+type edit struct {
+	Start   token.Position
+	End     token.Position
+	NewText string
+}
+
+// reportFlags are the flags only supported when running with runReport.
+var reportFlags = []string{"format", "baseline", "write-baseline", "stats", "fail-on"}
+
+// singlecheckerFlags are the flags only supported when running with
+// singlechecker.
+var singlecheckerFlags = []string{
+	"V", "flags", "json", "c", "fix", "diff", "debug", "cpuprofile", "memprofile", "trace",
+}
+
+// useSinglechecker reports if the arguments must be handled by singlechecker,
+// i.e. when invoked by go vet, for help or with a flag only singlechecker
+// supports. All other runs go through runReport so the exit code only depends
+// on issues with error severity, even without -format.
+func useSinglechecker(args []string) bool {
+	switch {
+	case len(args) == 0:
+		return true
+	case len(args) == 1 && strings.HasSuffix(args[0], ".cfg"):
+		return true
+	case args[0] == "help":
+		return true
+	}
+
+	return !hasFlag(args, reportFlags) && hasFlag(args, singlecheckerFlags)
+}
+
+// hasFlag reports if any of the flags in names is passed. Since we don't know
+// which flags takes a value, all arguments are checked.
+func hasFlag(args, names []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if slices.Contains(names, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runReport loads and analyzes the packages matching the patterns in args and
+// writes all issues in the format set with -format, text by default. Like
+// singlechecker the exit code is 3 if issues are found with the text format,
+// but only for issues with the severity set with -fail-on or higher. Other
+// formats works like -json and only exits with 1 if the packages couldn't be
+// loaded or analyzed.
+func runReport(args []string, stdout, stderr io.Writer) int {
+	analyzer := wsl.NewAnalyzer(nil)
+
+	var (
+		format        string
+		includeTests  bool
+		baselineFile  string
+		writeBaseline string
+		statsFormat   statsFlag
+		failOn        = wsl.SeverityError
+	)
+
+	flags := flag.NewFlagSet("wsl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&format, "format", "text", "output format, one of "+strings.Join(slices.Sorted(maps.Keys(reporters)), ", "))
+	flags.BoolVar(&includeTests, "test", true, "indicates whether test files should be analyzed, too")
+	flags.StringVar(&baselineFile, "baseline", "", "path to baseline file with issues to suppress")
+	flags.StringVar(&writeBaseline, "write-baseline", "", "write all issues to this baseline file and exit")
+	flags.Var(&statsFormat, "stats", "print the number of issues per check, reason, package and directory instead of the issues, as a 'table' (default) or 'json'")
+	flags.Func("fail-on", "exit with 3 if issues with this `severity` or higher are found with the text format, one of 'error' (default), 'warning' or 'info'", func(value string) error {
+		severity, err := wsl.SeverityFromString(value)
+		if err != nil {
+			return err
+		}
+
+		if severity == wsl.SeverityOff {
+			return fmt.Errorf("invalid severity '%s', must be 'error', 'warning' or 'info'", value)
+		}
+
+		failOn = severity
+
+		return nil
+	})
+	analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: wsl [-format <format>] [-baseline <file>] [flags] [package ...]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	reporter, ok := reporters[format]
+	if !ok {
+		fmt.Fprintf(stderr, "error: invalid format '%s'\n", format)
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	r, exitCode := analyze(analyzer, flags.Args(), includeTests, stderr)
+
+	if writeBaseline != "" {
+		b := newBaseline(r)
+		if err := b.write(writeBaseline); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+
+		fmt.Fprintf(stderr, "wrote %d issues to %s\n", len(r.Issues), writeBaseline)
+
+		return exitCode
+	}
+
+	if baselineFile != "" {
+		b, err := readBaseline(baselineFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+
+		for _, entry := range b.apply(r) {
+			fmt.Fprintf(stderr, "%s: stale baseline entry for %s, %d %s no longer reported: %s\n",
+				entry.File, cmp.Or(entry.Function, "file scope"), entry.Count, pluralize("issue", entry.Count), entry.Message)
+		}
+	}
+
+	if statsFormat != "" {
+		if err := writeStats(stdout, r, string(statsFormat)); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+
+		return exitCode
+	}
+
+	if err := reporter(stdout, r); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	failed := slices.ContainsFunc(r.Issues, func(i issue) bool {
+		return i.Severity <= failOn
+	})
+
+	if format == "text" && exitCode == 0 && failed {
+		exitCode = 3
+	}
+
+	return exitCode
+}
+
+// analyze runs the analyzer on all packages matching the patterns and returns
+// a report with the issues sorted by position. Errors are written to stderr and
+// reflected in the returned exit code.
+func analyze(analyzer *analysis.Analyzer, patterns []string, includeTests bool, stderr io.Writer) (*report, int) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:  packages.LoadSyntax | packages.NeedModule,
+		Tests: includeTests,
+	}, patterns...)
+	if err == nil && len(pkgs) == 0 {
+		err = fmt.Errorf("%s matched no packages", strings.Join(patterns, " "))
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return &report{}, 1
+	}
+
+	exitCode := 0
+
+	// Like singlechecker, package errors are printed but we still analyze
+	// since the analyzer runs despite errors.
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			fmt.Fprintln(stderr, err)
+
+			exitCode = 1
+		}
+	})
+
+	graph, err := checker.Analyze([]*analysis.Analyzer{analyzer}, pkgs, nil)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return &report{}, 1
+	}
+
+	checks := map[string]wsl.CheckType{}
+	for check := range wsl.CheckTypes() {
+		checks[check.String()] = check
+	}
+
+	var (
+		r        = &report{Files: map[string]struct{}{}}
+		analyzed = map[string]struct{}{}
+		seen     = map[string]struct{}{}
+	)
+
+	for _, act := range graph.Roots {
+		// The test main package generated by go test isn't a package of the
+		// module, skip it so it's not reported as a package or test suite.
+		if isTestMain(act.Package) {
+			continue
+		}
+
+		analyzed[act.Package.PkgPath] = struct{}{}
+
+		if act.Err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", act.Package.PkgPath, act.Err)
+
+			exitCode = 1
+
+			continue
+		}
+
+		fset := act.Package.Fset
+		files := map[string]*ast.File{}
+
+		for _, file := range act.Package.Syntax {
+			filename := fset.File(file.Pos()).Name()
+			files[filename] = file
+			r.Files[filename] = struct{}{}
+		}
+
+		for _, diag := range act.Diagnostics {
+			i := newIssue(fset, diag, checks[diag.Category])
+			i.Package = act.Package.PkgPath
+			i.Function = enclosingFunction(files[i.Position.Filename], diag.Pos)
+
+			// Files are analyzed both as part of the package and the test
+			// variant of the package.
+			key := fmt.Sprintf("%s:%s:%s", i.Position, diag.Category, i.Message)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			r.Issues = append(r.Issues, i)
+		}
+	}
+
+	r.Packages = slices.Sorted(maps.Keys(analyzed))
+
+	slices.SortFunc(r.Issues, func(a, b issue) int {
+		return cmp.Or(
+			cmp.Compare(a.Position.Filename, b.Position.Filename),
+			cmp.Compare(a.Position.Offset, b.Position.Offset),
+			cmp.Compare(a.Check, b.Check),
+		)
+	})
+
+	return r, exitCode
+}
+
+// isTestMain reports if the package is the test main package generated by go
+// test, e.g. `example.com/pkg.test`, which only has generated files.
+func isTestMain(pkg *packages.Package) bool {
+	if !strings.HasSuffix(pkg.PkgPath, ".test") || len(pkg.Syntax) == 0 {
+		return false
+	}
+
+	for _, file := range pkg.Syntax {
+		if !ast.IsGenerated(file) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newIssue(fset *token.FileSet, diag analysis.Diagnostic, check wsl.CheckType) issue {
+	i := issue{
+		Position: fset.Position(diag.Pos),
+		Check:    check,
+		Severity: wsl.SeverityError,
+		Message:  diag.Message,
+	}
+
+	// The reason is a query parameter in the URL.
+	if u, err := url.Parse(diag.URL); err == nil {
+		i.Reason, _ = wsl.ReasonFromString(u.Query().Get("reason"))
+	}
+
+	// Severities other than error are prefixed to the message.
+	for _, severity := range []wsl.Severity{wsl.SeverityWarning, wsl.SeverityInfo} {
+		if message, ok := strings.CutPrefix(diag.Message, severity.String()+": "); ok {
+			i.Severity = severity
+			i.Message = message
+
+			break
+		}
+	}
+
+	for _, fix := range diag.SuggestedFixes {
+		for _, e := range fix.TextEdits {
+			end := e.End
+			if !end.IsValid() {
+				end = e.Pos
+			}
+
+			i.Edits = append(i.Edits, edit{
+				Start:   fset.Position(e.Pos),
+				End:     fset.Position(end),
+				NewText: string(e.NewText),
+			})
+		}
+	}
+
+	return i
+}
+
+// enclosingFunction returns the name of the function declaration containing
+// pos, prefixed with the receiver type for methods.
+func enclosingFunction(file *ast.File, pos token.Pos) string {
+	if file == nil {
+		return ""
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || pos < fn.Pos() || pos > fn.End() {
+			continue
+		}
+
+		if fn.Recv == nil || len(fn.Recv.List) == 0 {
+			return fn.Name.Name
+		}
+
+		return types.ExprString(receiverType(fn.Recv.List[0].Type)) + "." + fn.Name.Name
+	}
+
+	return ""
+}
+
+// receiverType returns the receiver type without pointer and type parameters.
+func receiverType(expr ast.Expr) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		default:
+			return expr
+		}
+	}
+}
+
+// writeText writes the issues the same way as singlechecker.
+func writeText(w io.Writer, r *report) error {
+	for _, i := range r.Issues {
+		message := i.Message
+		if i.Severity != wsl.SeverityError {
+			message = i.Severity.String() + ": " + message
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %s\n", i.Position, message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// relativePath returns filename relative to the working directory if the file
+// is within it.
+func relativePath(wd, filename string) (string, bool) {
+	if wd == "" {
+		return filename, false
+	}
+
+	rel, err := filepath.Rel(wd, filename)
+	if err != nil || !filepath.IsLocal(rel) {
+		return filename, false
+	}
+
+	return rel, true
+}
+
+// sourceCache reads and caches the content of files referenced by issues.
+type sourceCache map[string][]byte
+
+func (c sourceCache) get(filename string) []byte {
+	src, ok := c[filename]
+	if !ok {
+		src, _ = os.ReadFile(filename)
+		c[filename] = src
+	}
+
+	return src
+}
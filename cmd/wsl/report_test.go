@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+// newModule creates a module with a single package containing src and
+// changes the working directory to it.
+func newModule(t *testing.T, src string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/report\n\ngo 1.24\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o600))
+
+	t.Chdir(dir)
+
+	return dir
+}
+
+func TestUseSinglechecker(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		args     []string
+		expected bool
+	}{
+		{args: []string{}, expected: true},
+		{args: []string{"help"}, expected: true},
+		{args: []string{"vet.cfg"}, expected: true},
+		{args: []string{"-fix", "./..."}, expected: true},
+		{args: []string{"-json", "./..."}, expected: true},
+		{args: []string{"-c=1", "./..."}, expected: true},
+		{args: []string{"./..."}},
+		{args: []string{"-severity", "after-block=warning,return=warning", "./..."}},
+		{args: []string{"-format", "sarif", "./..."}},
+		{args: []string{"-enable", "err", "--format=sarif", "./..."}},
+		{args: []string{"-baseline", "wsl-baseline.json", "./..."}},
+		{args: []string{"-write-baseline=wsl-baseline.json", "./..."}},
+		{args: []string{"-fail-on", "warning", "./..."}},
+		{args: []string{"-json", "-format", "sarif", "./..."}},
+		{args: []string{"--", "-fix"}},
+	} {
+		assert.Equal(t, tc.expected, useSinglechecker(tc.args), tc.args)
+	}
+}
+
+func TestReportDefaultFormat(t *testing.T) {
+	newModule(t, unformatted)
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runReport([]string{"-severity", "if=warning,assign=warning,err=warning,range=warning,leading-whitespace=warning", "./..."}, &stdout, &stderr)
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.Equal(t, 5, strings.Count(stdout.String(), ": warning: "))
+}
+
+func TestReportText(t *testing.T) {
+	dir := newModule(t, unformatted)
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runReport([]string{"-format", "text", "-severity", "err=warning", "./..."}, &stdout, &stderr)
+	require.Equal(t, 3, exitCode, stderr.String())
+
+	filename := filepath.Join(dir, "main.go")
+	assert.Equal(t, filename+":10:2: missing whitespace above this line (no shared variables above if)\n"+
+		filename+":13:2: missing whitespace above this line (invalid statement above assign)\n"+
+		filename+":14:1: warning: unnecessary whitespace (err)\n"+
+		filename+":18:2: missing whitespace above this line (invalid statement above range)\n"+
+		filename+":19:1: unnecessary whitespace (leading-whitespace)\n", stdout.String())
+}
+
+func TestReportFailOn(t *testing.T) {
+	const severities = "if=warning,assign=info,err=warning,range=info,leading-whitespace=warning"
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{name: "errors", expected: 3},
+		{name: "warnings and info", args: []string{"-severity", severities}},
+		{name: "fail on warning", args: []string{"-severity", severities, "-fail-on", "warning"}, expected: 3},
+		{name: "fail on info", args: []string{"-severity", severities, "-fail-on", "info"}, expected: 3},
+		{name: "only info", args: []string{"-severity", "if=info,assign=info,err=info,range=info,leading-whitespace=info", "-fail-on", "warning"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			newModule(t, unformatted)
+
+			var stdout, stderr bytes.Buffer
+
+			args := append([]string{"-format", "text"}, tc.args...)
+			exitCode := runReport(append(args, "./..."), &stdout, &stderr)
+
+			assert.Equal(t, tc.expected, exitCode, stderr.String())
+			assert.Len(t, bytes.Split(bytes.TrimSpace(stdout.Bytes()), []byte("\n")), 5)
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, 2, runReport([]string{"-fail-on", "off", "./..."}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "invalid severity 'off'")
+}
+
+func TestReportSARIF(t *testing.T) {
+	newModule(t, unformatted)
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runReport([]string{"-format", "sarif", "-severity", "err=warning", "./..."}, &stdout, &stderr)
+	require.Equal(t, 0, exitCode, stderr.String())
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	rules := run.Tool.Driver.Rules
+
+	assert.Equal(t, "wsl", run.Tool.Driver.Name)
+	assert.Len(t, rules, 36)
+	assert.Contains(t, run.OriginalURIBaseIDs, "%SRCROOT%")
+
+	require.Len(t, run.Results, 5)
+
+	for _, result := range run.Results {
+		assert.Equal(t, result.RuleID, rules[result.RuleIndex].ID)
+		assert.NotEmpty(t, rules[result.RuleIndex].ShortDescription.Text)
+		assert.Equal(t, sarifArtifactLocation{URI: "main.go", URIBaseID: "%SRCROOT%"}, result.Locations[0].PhysicalLocation.ArtifactLocation)
+		assert.Len(t, result.Fixes, 1)
+	}
+
+	first := run.Results[0]
+	assert.Equal(t, "if", first.RuleID)
+	assert.Equal(t, "error", first.Level)
+	assert.Equal(t, sarifRegion{StartLine: 10, StartColumn: 2}, first.Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, []sarifReplacement{{
+		DeletedRegion:   sarifRegion{StartLine: 10, StartColumn: 1, EndLine: 10, EndColumn: 1},
+		InsertedContent: &sarifMessage{Text: "\n"},
+	}}, first.Fixes[0].ArtifactChanges[0].Replacements)
+
+	errResult := run.Results[2]
+	assert.Equal(t, "err", errResult.RuleID)
+	assert.Equal(t, "warning", errResult.Level)
+	assert.Equal(t, "unnecessary whitespace (err)", errResult.Message.Text)
+}
+
+func TestReportErrors(t *testing.T) {
+	newModule(t, unformatted)
+
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, 2, runReport([]string{"-format", "xml", "./..."}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "invalid format 'xml'")
+
+	stderr.Reset()
+
+	assert.Equal(t, 1, runReport([]string{"-format", "text", "./missing"}, &stdout, &stderr))
+	assert.NotEmpty(t, stderr.String())
+}
+
+func TestUTF16Column(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("a\n\tå😀b\n")
+
+	assert.Equal(t, 1, utf16Column(src, token.Position{Offset: 2, Line: 2, Column: 1}))
+	assert.Equal(t, 2, utf16Column(src, token.Position{Offset: 3, Line: 2, Column: 2}))
+	assert.Equal(t, 5, utf16Column(src, token.Position{Offset: 9, Line: 2, Column: 8}))
+	assert.Equal(t, 3, utf16Column(nil, token.Position{Offset: 9, Line: 2, Column: 3}))
+}
+
+func TestIsTestMain(t *testing.T) {
+	t.Parallel()
+
+	parse := func(src string) *ast.File {
+		file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.PackageClauseOnly|parser.ParseComments)
+		require.NoError(t, err)
+
+		return file
+	}
+
+	generated := parse("// Code generated by 'go test'. DO NOT EDIT.\n\npackage main\n")
+	handwritten := parse("package main\n")
+
+	for _, tc := range []struct {
+		pkg      *packages.Package
+		expected bool
+	}{
+		{pkg: &packages.Package{PkgPath: "example.com/x.test", Syntax: []*ast.File{generated}}, expected: true},
+		{pkg: &packages.Package{PkgPath: "example.com/x.test", Syntax: []*ast.File{generated, handwritten}}},
+		{pkg: &packages.Package{PkgPath: "example.com/x.test"}},
+		{pkg: &packages.Package{PkgPath: "example.com/x", Syntax: []*ast.File{generated}}},
+		{pkg: &packages.Package{PkgPath: "example.com/x_test", Syntax: []*ast.File{handwritten}}},
+	} {
+		assert.Equal(t, tc.expected, isTestMain(tc.pkg), tc.pkg.PkgPath)
+	}
+}
+
+func TestReportCheckstyle(t *testing.T) {
+	newModule(t, unformatted)
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runReport([]string{"-format", "checkstyle", "-severity", "err=warning", "./..."}, &stdout, &stderr)
+	require.Equal(t, 0, exitCode, stderr.String())
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="5.0">
+  <file name="main.go">
+    <error line="10" column="2" severity="error" message="missing whitespace above this line (no shared variables above if)" source="wsl.if"></error>
+    <error line="13" column="2" severity="error" message="missing whitespace above this line (invalid statement above assign)" source="wsl.assign"></error>
+    <error line="14" column="1" severity="warning" message="unnecessary whitespace (err)" source="wsl.err"></error>
+    <error line="18" column="2" severity="error" message="missing whitespace above this line (invalid statement above range)" source="wsl.range"></error>
+    <error line="19" column="1" severity="error" message="unnecessary whitespace (leading-whitespace)" source="wsl.leading-whitespace"></error>
+  </file>
+</checkstyle>
+`, stdout.String())
+}
+
+func TestReportJUnit(t *testing.T) {
+	dir := newModule(t, unformatted)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "clean"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "clean", "clean.go"), []byte("package clean\n\nfunc F() {}\n"), 0o600))
+
+	// The test main package generated by go test is not a test suite.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "clean", "clean_test.go"), []byte("package clean\n\nimport \"testing\"\n\nfunc TestF(t *testing.T) {\n\tF()\n}\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runReport([]string{"-format", "junit", "-disable", "range,leading-whitespace", "./..."}, &stdout, &stderr)
+	require.Equal(t, 0, exitCode, stderr.String())
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="wsl" tests="4" failures="3">
+  <testsuite name="example.com/report" tests="3" failures="3">
+    <testcase name="assign" classname="example.com/report">
+      <failure message="1 issue" type="assign"><![CDATA[main.go:13:2: missing whitespace above this line (invalid statement above assign)
+]]></failure>
+    </testcase>
+    <testcase name="if" classname="example.com/report">
+      <failure message="1 issue" type="if"><![CDATA[main.go:10:2: missing whitespace above this line (no shared variables above if)
+]]></failure>
+    </testcase>
+    <testcase name="err" classname="example.com/report">
+      <failure message="1 issue" type="err"><![CDATA[main.go:14:1: unnecessary whitespace (err)
+]]></failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="example.com/report/clean" tests="1" failures="0">
+    <testcase name="wsl" classname="example.com/report/clean"></testcase>
+  </testsuite>
+</testsuites>
+`, stdout.String())
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"go/token"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/bombsimon/wsl/v5"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifSrcRoot = "%SRCROOT%"
+)
+
+// The SARIF types only contain the parts of the specification used by wsl,
+// see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+	ColumnKind         string                           `json:"columnKind"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	HelpURI              string                 `json:"helpUri"`
+	DefaultConfiguration sarifRuleConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion   `json:"deletedRegion"`
+	InsertedContent *sarifMessage `json:"insertedContent,omitempty"`
+}
+
+// writeSARIF writes the issues as a SARIF 2.1.0 log with one rule per check.
+// Paths within the working directory are relative to %SRCROOT% which is what
+// code scanning tools expect.
+func writeSARIF(w io.Writer, r *report) error {
+	wd, _ := os.Getwd()
+
+	var (
+		rules     []sarifRule
+		ruleIndex = map[wsl.CheckType]int{}
+	)
+
+	for check := range wsl.CheckTypes() {
+		ruleIndex[check] = len(rules)
+		rules = append(rules, sarifRule{
+			ID:                   check.String(),
+			ShortDescription:     sarifMessage{Text: check.Description()},
+			HelpURI:              check.DocURL(),
+			DefaultConfiguration: sarifRuleConfiguration{Level: sarifLevel(wsl.SeverityError)},
+		})
+	}
+
+	sources := sourceCache{}
+	results := make([]sarifResult, 0, len(r.Issues))
+
+	for _, i := range r.Issues {
+		artifact := sarifArtifact(wd, i.Position.Filename)
+		src := sources.get(i.Position.Filename)
+
+		result := sarifResult{
+			RuleID:    i.Check.String(),
+			RuleIndex: ruleIndex[i.Check],
+			Level:     sarifLevel(i.Severity),
+			Message:   sarifMessage{Text: i.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: artifact,
+					Region: sarifRegion{
+						StartLine:   i.Position.Line,
+						StartColumn: utf16Column(src, i.Position),
+					},
+				},
+			}},
+		}
+
+		if len(i.Edits) > 0 {
+			change := sarifArtifactChange{ArtifactLocation: artifact}
+
+			for _, e := range i.Edits {
+				replacement := sarifReplacement{
+					DeletedRegion: sarifRegion{
+						StartLine:   e.Start.Line,
+						StartColumn: utf16Column(src, e.Start),
+						EndLine:     e.End.Line,
+						EndColumn:   utf16Column(src, e.End),
+					},
+				}
+
+				if e.NewText != "" {
+					replacement.InsertedContent = &sarifMessage{Text: e.NewText}
+				}
+
+				change.Replacements = append(change.Replacements, replacement)
+			}
+
+			result.Fixes = []sarifFix{{
+				Description:     sarifMessage{Text: "Fix: " + i.Message},
+				ArtifactChanges: []sarifArtifactChange{change},
+			}}
+		}
+
+		results = append(results, result)
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "wsl",
+				Version:        wsl.Version,
+				InformationURI: "https://github.com/bombsimon/wsl",
+				Rules:          rules,
+			},
+		},
+		Results:    results,
+		ColumnKind: "utf16CodeUnits",
+	}
+
+	if wd != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			sarifSrcRoot: {URI: fileURI(wd) + "/"},
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
+
+func sarifLevel(severity wsl.Severity) string {
+	switch severity {
+	case wsl.SeverityWarning:
+		return "warning"
+	case wsl.SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// sarifArtifact returns the location of filename, relative to %SRCROOT% if the
+// file is within the working directory.
+func sarifArtifact(wd, filename string) sarifArtifactLocation {
+	if rel, ok := relativePath(wd, filename); ok {
+		return sarifArtifactLocation{
+			URI:       (&url.URL{Path: filepath.ToSlash(rel)}).String(),
+			URIBaseID: sarifSrcRoot,
+		}
+	}
+
+	return sarifArtifactLocation{URI: fileURI(filename)}
+}
+
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// utf16Column converts the byte based column in pos to a column counted in
+// UTF-16 code units which is the default column kind in SARIF.
+func utf16Column(src []byte, pos token.Position) int {
+	lineStart := pos.Offset - (pos.Column - 1)
+	if lineStart < 0 || pos.Offset > len(src) {
+		return pos.Column
+	}
+
+	column := 1
+
+	for line := src[lineStart:pos.Offset]; len(line) > 0; {
+		r, size := utf8.DecodeRune(line)
+		column += len(utf16.Encode([]rune{r}))
+		line = line[size:]
+	}
+
+	return column
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+)
+
+const (
+	statsTable = "table"
+	statsJSON  = "json"
+)
+
+// statsFlag is the value of -stats. It can be used as a boolean flag to print
+// the statistics as tables or be set to a format.
+type statsFlag string
+
+func (*statsFlag) IsBoolFlag() bool { return true }
+func (s *statsFlag) String() string { return string(*s) }
+
+func (s *statsFlag) Set(value string) error {
+	switch value {
+	case "true", statsTable:
+		*s = statsTable
+	case "false":
+		*s = ""
+	case statsJSON:
+		*s = statsJSON
+	default:
+		return fmt.Errorf("invalid stats format '%s', must be '%s' or '%s'", value, statsTable, statsJSON)
+	}
+
+	return nil
+}
+
+// stats holds the number of issues grouped by check, reason, package and
+// directory. Directories are relative to the working directory when possible.
+type stats struct {
+	Total       int          `json:"total"`
+	Fixable     int          `json:"fixable"`
+	Checks      []statsCount `json:"checks"`
+	Reasons     []statsCount `json:"reasons"`
+	Packages    []statsCount `json:"packages"`
+	Directories []statsCount `json:"directories"`
+}
+
+type statsCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func newStats(r *report) *stats {
+	var (
+		s           = &stats{Total: len(r.Issues)}
+		checks      = map[string]int{}
+		reasons     = map[string]int{}
+		packages    = map[string]int{}
+		directories = map[string]int{}
+	)
+
+	wd, _ := os.Getwd()
+
+	for _, i := range r.Issues {
+		if len(i.Edits) > 0 {
+			s.Fixable++
+		}
+
+		checks[i.Check.String()]++
+		reasons[i.Reason.String()]++
+		packages[i.Package]++
+
+		filename, _ := relativePath(wd, i.Position.Filename)
+		directories[filepath.ToSlash(filepath.Dir(filename))]++
+	}
+
+	s.Checks = sortedCounts(checks)
+	s.Reasons = sortedCounts(reasons)
+	s.Packages = sortedCounts(packages)
+	s.Directories = sortedCounts(directories)
+
+	return s
+}
+
+// sortedCounts returns the counts with the highest count first.
+func sortedCounts(counts map[string]int) []statsCount {
+	result := make([]statsCount, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, statsCount{Name: name, Count: count})
+	}
+
+	slices.SortFunc(result, func(a, b statsCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Name, b.Name))
+	})
+
+	return result
+}
+
+func writeStats(w io.Writer, r *report, format string) error {
+	s := newStats(r)
+
+	if format == statsJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(s)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for n, table := range []struct {
+		title  string
+		counts []statsCount
+	}{
+		{title: "CHECK", counts: s.Checks},
+		{title: "REASON", counts: s.Reasons},
+		{title: "PACKAGE", counts: s.Packages},
+		{title: "DIRECTORY", counts: s.Directories},
+	} {
+		if n > 0 {
+			fmt.Fprintln(tw)
+		}
+
+		fmt.Fprintf(tw, "%s\tISSUES\tSHARE\n", table.title)
+
+		for _, c := range table.counts {
+			fmt.Fprintf(tw, "%s\t%d\t%.1f%%\n", c.Name, c.Count, 100*float64(c.Count)/float64(s.Total))
+		}
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "TOTAL\t%d\n", s.Total)
+	fmt.Fprintf(tw, "FIXABLE\t%d\n", s.Fixable)
+
+	return tw.Flush()
+}
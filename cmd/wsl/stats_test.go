@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	newModule(t, unformatted)
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runReport([]string{"-stats", "./..."}, &stdout, &stderr)
+	require.Equal(t, 0, exitCode, stderr.String())
+
+	assert.Equal(t, `CHECK               ISSUES  SHARE
+assign              1       20.0%
+err                 1       20.0%
+if                  1       20.0%
+leading-whitespace  1       20.0%
+range               1       20.0%
+
+REASON               ISSUES  SHARE
+invalid-type-cuddle  2       40.0%
+remove-whitespace    2       40.0%
+no-intersection      1       20.0%
+
+PACKAGE             ISSUES  SHARE
+example.com/report  5       100.0%
+
+DIRECTORY  ISSUES  SHARE
+.          5       100.0%
+
+TOTAL    5
+FIXABLE  5
+`, stdout.String())
+}
+
+func TestStatsJSON(t *testing.T) {
+	newModule(t, unformatted)
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runReport([]string{"-stats=json", "-disable", "leading-whitespace,range", "./..."}, &stdout, &stderr)
+	require.Equal(t, 0, exitCode, stderr.String())
+
+	var s stats
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &s))
+
+	assert.Equal(t, stats{
+		Total:   3,
+		Fixable: 3,
+		Checks: []statsCount{
+			{Name: "assign", Count: 1},
+			{Name: "err", Count: 1},
+			{Name: "if", Count: 1},
+		},
+		Reasons: []statsCount{
+			{Name: "invalid-type-cuddle", Count: 1},
+			{Name: "no-intersection", Count: 1},
+			{Name: "remove-whitespace", Count: 1},
+		},
+		Packages: []statsCount{
+			{Name: "example.com/report", Count: 3},
+		},
+		Directories: []statsCount{
+			{Name: ".", Count: 3},
+		},
+	}, s)
+}
+
+func TestStatsDirectories(t *testing.T) {
+	dir := newModule(t, unformatted)
+
+	for _, sub := range []string{"a", filepath.Join("a", "b")} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, sub, "main.go"), []byte(strings.Replace(unformatted, "package main", "package "+filepath.Base(sub), 1)), 0o600))
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runReport([]string{"-stats=json", "-disable", "leading-whitespace,range", "./..."}, &stdout, &stderr)
+	require.Equal(t, 0, exitCode, stderr.String())
+
+	var s stats
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &s))
+
+	assert.Equal(t, []statsCount{
+		{Name: ".", Count: 3},
+		{Name: "a", Count: 3},
+		{Name: "a/b", Count: 3},
+	}, s.Directories)
+	assert.Equal(t, []statsCount{
+		{Name: "example.com/report", Count: 3},
+		{Name: "example.com/report/a", Count: 3},
+		{Name: "example.com/report/a/b", Count: 3},
+	}, s.Packages)
+}
+
+func TestStatsFlag(t *testing.T) {
+	t.Parallel()
+
+	var s statsFlag
+
+	require.NoError(t, s.Set("true"))
+	assert.Equal(t, statsTable, s.String())
+
+	require.NoError(t, s.Set("json"))
+	assert.Equal(t, statsJSON, s.String())
+
+	require.NoError(t, s.Set("false"))
+	assert.Empty(t, s.String())
+
+	require.ErrorContains(t, s.Set("xml"), "invalid stats format 'xml'")
+}
@@ -0,0 +1,92 @@
+package wsl
+
+import (
+	"cmp"
+	"go/ast"
+	"go/token"
+	"slices"
+	"sort"
+)
+
+// commentIndex holds all comment groups in a file sorted by position together
+// with the line each group starts on. It's built once per file so finding
+// comments in a range is a binary search instead of iterating over all
+// comments in the file for every statement.
+type commentIndex struct {
+	groups []*ast.CommentGroup
+	lines  []int
+}
+
+func newCommentIndex(fset *token.FileSet, groups []*ast.CommentGroup) *commentIndex {
+	// The parser always adds comments in order but files constructed by hand
+	// might not.
+	if !slices.IsSortedFunc(groups, compareCommentGroups) {
+		groups = slices.SortedFunc(slices.Values(groups), compareCommentGroups)
+	}
+
+	lines := make([]int, len(groups))
+	for i, cg := range groups {
+		lines[i] = fset.PositionFor(cg.Pos(), false).Line
+	}
+
+	return &commentIndex{
+		groups: groups,
+		lines:  lines,
+	}
+}
+
+func compareCommentGroups(a, b *ast.CommentGroup) int {
+	return cmp.Compare(a.Pos(), b.Pos())
+}
+
+// after returns all comment groups ending after pos.
+func (ci *commentIndex) after(pos token.Pos) []*ast.CommentGroup {
+	i := sort.Search(len(ci.groups), func(i int) bool {
+		return ci.groups[i].End() > pos
+	})
+
+	return ci.groups[i:]
+}
+
+// between returns all comment groups starting at or after start and before
+// end.
+func (ci *commentIndex) between(start, end token.Pos) []*ast.CommentGroup {
+	i := ci.search(start)
+	j := max(i, ci.search(end))
+
+	return ci.groups[i:j]
+}
+
+// enclosing returns the comment group containing pos or nil if pos isn't
+// within a comment.
+func (ci *commentIndex) enclosing(pos token.Pos) *ast.CommentGroup {
+	i := ci.search(pos + 1)
+	if i == 0 {
+		return nil
+	}
+
+	if cg := ci.groups[i-1]; pos < cg.End() {
+		return cg
+	}
+
+	return nil
+}
+
+// onLine returns the first comment group starting on line or nil if no comment
+// group starts on that line.
+func (ci *commentIndex) onLine(line int) *ast.CommentGroup {
+	i, found := slices.BinarySearch(ci.lines, line)
+	if !found {
+		return nil
+	}
+
+	return ci.groups[i]
+}
+
+// search returns the index of the first comment group starting at or after
+// pos.
+func (ci *commentIndex) search(pos token.Pos) int {
+	return sort.Search(len(ci.groups), func(i int) bool {
+		return ci.groups[i].Pos() >= pos
+	})
+}
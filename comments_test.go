@@ -0,0 +1,143 @@
+package wsl
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentIndex(t *testing.T) {
+	t.Parallel()
+
+	src := `package main
+
+// A is a doc comment.
+var a = 1 // Trailing comment.
+
+/* Block */ var b = 2
+
+// Free floating
+// comment group.
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	require.NoError(t, err)
+	require.Len(t, file.Comments, 4)
+
+	var (
+		doc, trailing, block, floating = file.Comments[0], file.Comments[1], file.Comments[2], file.Comments[3]
+		varA, varB                     = file.Decls[0], file.Decls[1]
+	)
+
+	// Comments are sorted even if the file isn't.
+	reversed := slices.Clone(file.Comments)
+	slices.Reverse(reversed)
+
+	for _, ci := range []*commentIndex{
+		newCommentIndex(fset, file.Comments),
+		newCommentIndex(fset, reversed),
+	} {
+		assert.Equal(t, file.Comments, ci.groups)
+		assert.Equal(t, []int{3, 4, 6, 8}, ci.lines)
+
+		assert.Equal(t, file.Comments, ci.after(file.FileStart))
+		assert.Equal(t, []*ast.CommentGroup{trailing, block, floating}, ci.after(varA.Pos()))
+		assert.Equal(t, []*ast.CommentGroup{floating}, ci.after(varB.End()))
+		assert.Empty(t, ci.after(floating.End()))
+
+		assert.Equal(t, []*ast.CommentGroup{trailing, block}, ci.between(varA.End(), varB.Pos()))
+		assert.Equal(t, []*ast.CommentGroup{doc}, ci.between(file.FileStart, varA.Pos()))
+		assert.Empty(t, ci.between(varB.Pos(), varB.End()))
+		assert.Empty(t, ci.between(varB.End(), varA.Pos()))
+
+		assert.Equal(t, floating, ci.enclosing(floating.List[1].Pos()))
+		assert.Equal(t, block, ci.enclosing(block.Pos()))
+		assert.Equal(t, block, ci.enclosing(block.End()-1))
+		assert.Nil(t, ci.enclosing(block.End()))
+		assert.Nil(t, ci.enclosing(varA.Pos()))
+		assert.Nil(t, ci.enclosing(file.FileStart))
+
+		assert.Equal(t, trailing, ci.onLine(4))
+		assert.Equal(t, floating, ci.onLine(8))
+		assert.Nil(t, ci.onLine(5))
+		assert.Nil(t, ci.onLine(9))
+	}
+}
+
+// commentHeavySource generates a file with n functions where every statement
+// has comments above, after and inside of it.
+func commentHeavySource(n int) string {
+	var sb strings.Builder
+
+	sb.WriteString("package main\n\nimport \"fmt\"\n\n")
+
+	for i := range n {
+		fmt.Fprintf(&sb, `// Fn%[1]d does things.
+func Fn%[1]d(err error) error {
+	// Leading comment.
+	a := %[1]d // Trailing comment.
+	// Comment above if.
+	if a > 0 {
+		// Comment in block.
+		fmt.Println(a)
+		// Trailing comment in block.
+	} else {
+		// Comment in else.
+		fmt.Println("else")
+	}
+	// Comment after block.
+	switch a {
+	case 1:
+		fmt.Println(a)
+		// Indented comment.
+	// Left aligned comment.
+	case 2:
+		fmt.Println(a)
+	}
+	err = fmt.Errorf("%%w", err) // Comment on err.
+	if err != nil {
+		return err
+	}
+
+	// Comment before return.
+	return nil
+}
+
+`, i)
+	}
+
+	return sb.String()
+}
+
+func BenchmarkCommentHeavyFile(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("funcs=%d", n), func(b *testing.B) {
+			src := commentHeavySource(n)
+			fset := token.NewFileSet()
+
+			file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			cfg := NewConfig()
+			cfg.Checks = AllChecks()
+			cfg.CaseMaxLines = 1
+
+			b.ReportAllocs()
+
+			for b.Loop() {
+				w := newWSL(fset, file, nil, nil, cfg)
+				w.Run()
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package wsl
 
 import (
 	"fmt"
+	"iter"
+	"maps"
+	"slices"
 	"strings"
 )
 
@@ -103,8 +106,73 @@ const (
 	//nolint:godoclint // No need to document
 	// CheckTypes only used for reporting.
 	CheckCaseTrailingNewline
+
+	// CheckUnusedDirective reports `//wsl:ignore` and `//wsl:file-ignore`
+	// directives that doesn't suppress any issue.
+	CheckUnusedDirective
+	// CheckTopLevelDecl ensures top-level declarations of different kinds
+	// (`import`, `const`, `var`, `type`, `func`) are separated by an empty line
+	// and that doc comments aren't separated from their declaration.
+	CheckTopLevelDecl
+	// CheckDeclGroup disallows leading and trailing empty lines in grouped
+	// declarations, e.g. `var ( ... )`, and ensures that doc comments aren't
+	// separated from the spec they document.
+	CheckDeclGroup
+	// CheckTypeWhitespace disallows leading and trailing empty lines in struct
+	// and interface types.
+	CheckTypeWhitespace
+	// CheckEmbeddedField ensures embedded fields in structs and interfaces are
+	// separated from named fields by an empty line, e.g.
+	//
+	// type T struct {
+	//     sync.Mutex
+	//
+	//     name string
+	// }
+	// .
+	CheckEmbeddedField
+	// CheckCompositeWhitespace disallows leading and trailing empty lines in
+	// composite literals, e.g. `[]int{ ... }` or `T{ ... }`.
+	CheckCompositeWhitespace
+	// CheckCallWhitespace disallows leading and trailing empty lines in
+	// multi-line function call arguments.
+	CheckCallWhitespace
+
+	// checkTypeCount is the number of check types, it must be last.
+	checkTypeCount
 )
 
+// CheckTypes returns an iterator over all check types in order, including the
+// ones only used for reporting.
+func CheckTypes() iter.Seq[CheckType] {
+	return func(yield func(CheckType) bool) {
+		for check := CheckAssign; check < checkTypeCount; check++ {
+			if !yield(check) {
+				return
+			}
+		}
+	}
+}
+
+// checksDocURL is the URL to the documentation of all checks.
+const checksDocURL = "https://github.com/bombsimon/wsl/blob/main/CHECKS.md"
+
+// DocURL returns the URL to the section documenting the check.
+func (c CheckType) DocURL() string {
+	return checksDocURL + "#" + c.docSection()
+}
+
+// docSection returns the name of the section in CHECKS.md documenting the
+// check.
+func (c CheckType) docSection() string {
+	// Reported when `case-max-lines` is configured.
+	if c == CheckCaseTrailingNewline {
+		return "case-max-lines"
+	}
+
+	return c.String()
+}
+
 func (c CheckType) String() string {
 	return [...]string{
 		"invalid",
@@ -139,9 +207,93 @@ func (c CheckType) String() string {
 		"trailing-whitespace",
 		//
 		"case-trailing-newline",
+		//
+		"unused-directive",
+		"top-level-decl",
+		"decl-group",
+		"type-whitespace",
+		"embedded-field",
+		"composite-whitespace",
+		"call-whitespace",
 	}[c]
 }
 
+// Severity is the severity a check is reported with.
+type Severity int
+
+// Each check is reported as an error unless configured otherwise. Setting a
+// check to SeverityOff is the same as disabling it.
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+	SeverityOff
+)
+
+func (s Severity) String() string {
+	return [...]string{
+		"error",
+		"warning",
+		"info",
+		"off",
+	}[s]
+}
+
+func SeverityFromString(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "error":
+		return SeverityError, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "info":
+		return SeverityInfo, nil
+	case "off":
+		return SeverityOff, nil
+	default:
+		return SeverityError, fmt.Errorf("invalid severity '%s'", s)
+	}
+}
+
+// Severities holds the severity for each check. Checks not present are
+// reported with SeverityError.
+type Severities map[CheckType]Severity
+
+// NewSeverities parses a list of `check=severity` pairs, e.g.
+// `after-block=warning`.
+func NewSeverities(values []string) (Severities, error) {
+	severities := Severities{}
+
+	for _, v := range values {
+		checkName, severityName, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid severity '%s', must be `check=severity`", v)
+		}
+
+		check, err := reportedCheckFromString(strings.TrimSpace(checkName))
+		if err != nil {
+			return nil, err
+		}
+
+		severity, err := SeverityFromString(strings.TrimSpace(severityName))
+		if err != nil {
+			return nil, err
+		}
+
+		severities[check] = severity
+	}
+
+	return severities, nil
+}
+
+// Get returns the severity for the check.
+func (s Severities) Get(check CheckType) Severity {
+	if severity, ok := s[check]; ok {
+		return severity
+	}
+
+	return SeverityError
+}
+
 type Configuration struct {
 	IncludeGenerated    bool
 	AllowFirstInBlock   bool
@@ -150,6 +302,38 @@ type Configuration struct {
 	CaseMaxLines        int
 	CuddleMaxStatements int
 	Checks              CheckSet
+	Severities          Severities
+
+	// SyntaxOnly ignores all type information and uses heuristics based on
+	// names instead, e.g. an identifier named `err` is assumed to be an error.
+	// This is useful when type information is slow or impossible to get.
+	SyntaxOnly bool
+
+	// ErrCheckSelector includes error fields, e.g. `if res.Err != nil`, in
+	// the `err` check.
+	ErrCheckSelector bool
+
+	// ErrCheckIsAs includes `errors.Is` and `errors.As` calls, e.g.
+	// `if errors.Is(err, io.EOF)`, in the `err` check.
+	ErrCheckIsAs bool
+
+	// ErrCheckCommaOk includes checking `ok` after a comma-ok assignment, e.g.
+	// `v, ok := m[k]` followed by `if !ok`, in the `err` check.
+	ErrCheckCommaOk bool
+
+	// LockMethods are the methods that can be cuddled above any statement,
+	// e.g. `mu.Lock()`. If nil, DefaultLockMethods is used.
+	LockMethods []string
+
+	// UnlockMethods are the methods that can be cuddled below any statement,
+	// e.g. `mu.Unlock()`. If nil, DefaultUnlockMethods is used.
+	UnlockMethods []string
+
+	// LockTypes restricts the lock and unlock methods to receivers of these
+	// types, e.g. `golang.org/x/sync/semaphore.Weighted`. If the type is an
+	// interface, e.g. `sync.Locker`, all types implementing it are allowed. If
+	// empty, any receiver is allowed.
+	LockTypes []string
 }
 
 func NewConfig() *Configuration {
@@ -161,7 +345,25 @@ func NewConfig() *Configuration {
 		BranchMaxLines:      2,
 		CuddleMaxStatements: 1,
 		Checks:              DefaultChecks(),
+		Severities:          Severities{},
+		LockMethods:         slices.Clone(DefaultLockMethods),
+		UnlockMethods:       slices.Clone(DefaultUnlockMethods),
+	}
+}
+
+// setSeverities adds the severities to the configuration and removes all
+// checks with SeverityOff. A check previously turned off is enabled again when
+// given another severity, e.g. by an override or a flag applied on top of the
+// configuration file.
+func (c *Configuration) setSeverities(severities Severities) {
+	for check, severity := range severities {
+		if severity != SeverityOff && c.Severities.Get(check) == SeverityOff {
+			c.Checks.Add(check)
+		}
 	}
+
+	maps.Copy(c.Severities, severities)
+	c.Checks.ApplySeverities(c.Severities)
 }
 
 func NewWithChecks(
@@ -254,6 +456,13 @@ func AllChecks() CheckSet {
 	c.Add(CheckAfterExpr)
 	c.Add(CheckAfterGo)
 	c.Add(CheckCuddleGroup)
+	c.Add(CheckUnusedDirective)
+	c.Add(CheckTopLevelDecl)
+	c.Add(CheckDeclGroup)
+	c.Add(CheckTypeWhitespace)
+	c.Add(CheckEmbeddedField)
+	c.Add(CheckCompositeWhitespace)
+	c.Add(CheckCallWhitespace)
 
 	return c
 }
@@ -270,6 +479,38 @@ func (c CheckSet) Remove(check CheckType) {
 	delete(c, check)
 }
 
+// update adds all checks in enable and removes all checks in disable.
+func (c CheckSet) update(enable, disable []string) error {
+	for _, s := range enable {
+		check, err := CheckFromString(s)
+		if err != nil {
+			return err
+		}
+
+		c.Add(check)
+	}
+
+	for _, s := range disable {
+		check, err := CheckFromString(s)
+		if err != nil {
+			return err
+		}
+
+		c.Remove(check)
+	}
+
+	return nil
+}
+
+// ApplySeverities removes all checks with SeverityOff from the set.
+func (c CheckSet) ApplySeverities(severities Severities) {
+	for check, severity := range severities {
+		if severity == SeverityOff {
+			c.Remove(check)
+		}
+	}
+}
+
 func CheckFromString(s string) (CheckType, error) {
 	switch strings.ToLower(s) {
 	case "assign":
@@ -329,7 +570,32 @@ func CheckFromString(s string) (CheckType, error) {
 		return CheckLeadingWhitespace, nil
 	case "trailing-whitespace":
 		return CheckTrailingWhitespace, nil
+	case "unused-directive":
+		return CheckUnusedDirective, nil
+	case "top-level-decl":
+		return CheckTopLevelDecl, nil
+	case "decl-group":
+		return CheckDeclGroup, nil
+	case "type-whitespace":
+		return CheckTypeWhitespace, nil
+	case "embedded-field":
+		return CheckEmbeddedField, nil
+	case "composite-whitespace":
+		return CheckCompositeWhitespace, nil
+	case "call-whitespace":
+		return CheckCallWhitespace, nil
 	default:
 		return CheckInvalid, fmt.Errorf("invalid check '%s'", s)
 	}
 }
+
+// reportedCheckFromString is like CheckFromString but also accepts the checks
+// only used for reporting. They can't be enabled or disabled but issues
+// reported with them can be ignored and given a severity.
+func reportedCheckFromString(s string) (CheckType, error) {
+	if strings.EqualFold(s, CheckCaseTrailingNewline.String()) {
+		return CheckCaseTrailingNewline, nil
+	}
+
+	return CheckFromString(s)
+}
@@ -60,6 +60,11 @@ func TestCheckSet(t *testing.T) {
 			enable:              []string{"invalid-enable"},
 			expectedErrContains: "invalid check 'invalid-enable'",
 		},
+		{
+			defaultName:         "none",
+			enable:              []string{"case-trailing-newline"},
+			expectedErrContains: "invalid check 'case-trailing-newline'",
+		},
 		{
 			defaultName:         "invalid",
 			expectedErrContains: "invalid preset",
@@ -83,9 +88,7 @@ func TestCheckSet(t *testing.T) {
 func TestToAndFromString(t *testing.T) {
 	t.Parallel()
 
-	maxCheckNumber := 29
-
-	for n := range maxCheckNumber {
+	for n := range int(checkTypeCount) {
 		check := CheckType(n)
 		ct, err := CheckFromString(check.String())
 
@@ -96,7 +99,100 @@ func TestToAndFromString(t *testing.T) {
 			continue
 		}
 
+		// Only used for reporting, can't be enabled.
+		if check == CheckCaseTrailingNewline {
+			require.Error(t, err)
+
+			continue
+		}
+
 		require.NoError(t, err)
 		assert.Equal(t, check, ct)
 	}
 }
+
+func TestDescription(t *testing.T) {
+	t.Parallel()
+
+	for check := range CheckTypes() {
+		assert.NotEmpty(t, check.Description(), check.String())
+	}
+
+	assert.Equal(t, "Blocks should not start with an empty line.", CheckLeadingWhitespace.Description())
+	assert.Equal(t, CheckCaseTrailingNewline.Description(), checkDescriptions()["case-max-lines"])
+}
+
+func TestSeverities(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name                string
+		values              []string
+		expectedSeverities  Severities
+		expectedErrContains string
+	}{
+		{
+			name:               "empty",
+			expectedSeverities: Severities{},
+		},
+		{
+			name:   "multiple",
+			values: []string{"after-block=warning", "err = error", "return=INFO", "assign=off"},
+			expectedSeverities: Severities{
+				CheckAfterBlock: SeverityWarning,
+				CheckErr:        SeverityError,
+				CheckReturn:     SeverityInfo,
+				CheckAssign:     SeverityOff,
+			},
+		},
+		{
+			name:               "reporting only check",
+			values:             []string{"case-trailing-newline=warning"},
+			expectedSeverities: Severities{CheckCaseTrailingNewline: SeverityWarning},
+		},
+		{
+			name:                "missing separator",
+			values:              []string{"after-block"},
+			expectedErrContains: "must be `check=severity`",
+		},
+		{
+			name:                "invalid check",
+			values:              []string{"invalid-check=warning"},
+			expectedErrContains: "invalid check 'invalid-check'",
+		},
+		{
+			name:                "invalid severity",
+			values:              []string{"err=fatal"},
+			expectedErrContains: "invalid severity 'fatal'",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			severities, err := NewSeverities(tc.values)
+			if tc.expectedErrContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErrContains)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedSeverities, severities)
+		})
+	}
+}
+
+func TestApplySeverities(t *testing.T) {
+	t.Parallel()
+
+	checks := DefaultChecks()
+	checks.ApplySeverities(Severities{
+		CheckErr:    SeverityOff,
+		CheckReturn: SeverityWarning,
+	})
+
+	assert.NotContains(t, checks, CheckErr)
+	assert.Contains(t, checks, CheckReturn)
+	assert.Equal(t, SeverityError, Severities{}.Get(CheckIf))
+}
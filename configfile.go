@@ -0,0 +1,542 @@
+package wsl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// ConfigFileNames are the names of the configuration files looked for when
+// walking up from the package directory, in order of precedence.
+//
+//nolint:gochecknoglobals // Used as a constant.
+var ConfigFileNames = []string{".wsl.yml", ".wsl.yaml", ".wsl.toml"}
+
+var errUnknownKey = errors.New("unknown key")
+
+// ConfigFile is the content of a configuration file.
+type ConfigFile struct {
+	ConfigSettings
+
+	Path string
+
+	// Overrides are applied in order on top of the settings above for all
+	// files matching any of their paths.
+	Overrides []ConfigOverride
+}
+
+// ConfigOverride holds settings that only applies to files matching any of
+// Paths. The paths are glob patterns relative to the directory of the
+// configuration file where `**` matches any number of directories, e.g.
+// `**/*_test.go`.
+type ConfigOverride struct {
+	ConfigSettings
+
+	Paths []string
+}
+
+// ConfigSettings are the settings in a configuration file or override. Only the
+// settings present are set, everything else is nil so it can be merged with
+// other settings and flags.
+type ConfigSettings struct {
+	IncludeGenerated    *bool
+	AllowFirstInBlock   *bool
+	AllowWholeBlock     *bool
+	BranchMaxLines      *int
+	CaseMaxLines        *int
+	CuddleMaxStatements *int
+	SyntaxOnly          *bool
+	ErrCheckSelector    *bool
+	ErrCheckIsAs        *bool
+	ErrCheckCommaOk     *bool
+	Default             *string
+	Enable              []string
+	Disable             []string
+	Severity            []string
+	LockMethods         []string
+	UnlockMethods       []string
+	LockTypes           []string
+}
+
+// FindConfigFile walks up from dir until it finds a configuration file and
+// returns the path to it. If no configuration file is found, an empty string
+// is returned.
+func FindConfigFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		for _, name := range ConfigFileNames {
+			path := filepath.Join(dir, name)
+
+			info, err := os.Stat(path)
+			if err == nil && !info.IsDir() {
+				return path, nil
+			}
+
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// LoadConfigFile reads and validates the configuration file at path. The format
+// is decided by the file extension, `.toml` for TOML and YAML otherwise.
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	raw := map[string]any{}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	cf, err := newConfigFile(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	cf.Path = path
+
+	return cf, nil
+}
+
+func newConfigFile(raw map[string]any) (*ConfigFile, error) {
+	cf := &ConfigFile{}
+
+	if value, ok := raw["overrides"]; ok {
+		overrides, err := configOverrides(value)
+		if err != nil {
+			return nil, err
+		}
+
+		cf.Overrides = overrides
+
+		delete(raw, "overrides")
+	}
+
+	settings, err := newConfigSettings(raw, "")
+	if err != nil {
+		return nil, err
+	}
+
+	cf.ConfigSettings = *settings
+
+	return cf, nil
+}
+
+func configOverrides(value any) ([]ConfigOverride, error) {
+	values, ok := value.([]any)
+	if !ok {
+		// TOML decodes arrays of tables to a slice of maps.
+		tables, ok := value.([]map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("key 'overrides': expected list of overrides, got '%v'", value)
+		}
+
+		for _, table := range tables {
+			values = append(values, table)
+		}
+	}
+
+	overrides := make([]ConfigOverride, 0, len(values))
+
+	for i, v := range values {
+		prefix := fmt.Sprintf("overrides[%d].", i)
+
+		raw, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("key '%s': expected map, got '%v'", strings.TrimSuffix(prefix, "."), v)
+		}
+
+		paths, err := configStrings(raw["paths"])
+		if err != nil || len(paths) == 0 {
+			return nil, fmt.Errorf("key '%spaths': expected non empty list of strings, got '%v'", prefix, raw["paths"])
+		}
+
+		for _, p := range paths {
+			if err := validateGlob(p); err != nil {
+				return nil, fmt.Errorf("key '%spaths': %w", prefix, err)
+			}
+		}
+
+		delete(raw, "paths")
+
+		settings, err := newConfigSettings(raw, prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		overrides = append(overrides, ConfigOverride{
+			ConfigSettings: *settings,
+			Paths:          paths,
+		})
+	}
+
+	return overrides, nil
+}
+
+// newConfigSettings creates settings from the raw values. The prefix is added to
+// the key in all errors.
+func newConfigSettings(raw map[string]any, prefix string) (*ConfigSettings, error) {
+	cs := &ConfigSettings{}
+
+	// Sort the keys to always report the same error first.
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if err := cs.set(key, raw[key]); errors.Is(err, errUnknownKey) {
+			return nil, fmt.Errorf("unknown key '%s%s'", prefix, key)
+		} else if err != nil {
+			return nil, fmt.Errorf("key '%s%s': %w", prefix, key, err)
+		}
+	}
+
+	// Validate the checks so we can point to the offending key instead of
+	// failing when running the analyzer.
+	for _, list := range []struct {
+		key    string
+		values []string
+	}{
+		{"enable", cs.Enable},
+		{"disable", cs.Disable},
+	} {
+		for _, v := range list.values {
+			if _, err := CheckFromString(v); err != nil {
+				return nil, fmt.Errorf("key '%s%s': %w", prefix, list.key, err)
+			}
+		}
+	}
+
+	if cs.Default != nil {
+		if _, err := NewCheckSet(*cs.Default, nil, nil); err != nil {
+			return nil, fmt.Errorf("key '%sdefault': %w", prefix, err)
+		}
+	}
+
+	if _, err := NewSeverities(cs.Severity); err != nil {
+		return nil, fmt.Errorf("key '%sseverity': %w", prefix, err)
+	}
+
+	for _, lockType := range cs.LockTypes {
+		if _, _, err := ParseLockType(lockType); err != nil {
+			return nil, fmt.Errorf("key '%slock-types': %w", prefix, err)
+		}
+	}
+
+	return cs, nil
+}
+
+//nolint:cyclop // One case per key.
+func (cs *ConfigSettings) set(key string, value any) error {
+	var err error
+
+	switch key {
+	case "include-generated":
+		cs.IncludeGenerated, err = configBool(value)
+	case "allow-first-in-block":
+		cs.AllowFirstInBlock, err = configBool(value)
+	case "allow-whole-block":
+		cs.AllowWholeBlock, err = configBool(value)
+	case "branch-max-lines":
+		cs.BranchMaxLines, err = configInt(value)
+	case "case-max-lines":
+		cs.CaseMaxLines, err = configInt(value)
+	case "cuddle-max-statements":
+		cs.CuddleMaxStatements, err = configInt(value)
+	case "syntax-only":
+		cs.SyntaxOnly, err = configBool(value)
+	case "err-check-selector":
+		cs.ErrCheckSelector, err = configBool(value)
+	case "err-check-is-as":
+		cs.ErrCheckIsAs, err = configBool(value)
+	case "err-check-comma-ok":
+		cs.ErrCheckCommaOk, err = configBool(value)
+	case "default":
+		cs.Default, err = configString(value)
+	case "enable":
+		cs.Enable, err = configStrings(value)
+	case "disable":
+		cs.Disable, err = configStrings(value)
+	case "severity":
+		cs.Severity, err = configSeverities(value)
+	case "lock-methods":
+		cs.LockMethods, err = configStrings(value)
+	case "unlock-methods":
+		cs.UnlockMethods, err = configStrings(value)
+	case "lock-types":
+		cs.LockTypes, err = configStrings(value)
+	default:
+		return errUnknownKey
+	}
+
+	return err
+}
+
+// Apply applies the settings to the configuration. If a preset is set with
+// `default` it replaces all checks, the checks in `enable` and `disable` are
+// then added to or removed from the checks in the configuration.
+func (cs *ConfigSettings) Apply(cfg *Configuration) error {
+	setIfNotNil(&cfg.IncludeGenerated, cs.IncludeGenerated)
+	setIfNotNil(&cfg.AllowFirstInBlock, cs.AllowFirstInBlock)
+	setIfNotNil(&cfg.AllowWholeBlock, cs.AllowWholeBlock)
+	setIfNotNil(&cfg.BranchMaxLines, cs.BranchMaxLines)
+	setIfNotNil(&cfg.CaseMaxLines, cs.CaseMaxLines)
+	setIfNotNil(&cfg.CuddleMaxStatements, cs.CuddleMaxStatements)
+	setIfNotNil(&cfg.SyntaxOnly, cs.SyntaxOnly)
+	setIfNotNil(&cfg.ErrCheckSelector, cs.ErrCheckSelector)
+	setIfNotNil(&cfg.ErrCheckIsAs, cs.ErrCheckIsAs)
+	setIfNotNil(&cfg.ErrCheckCommaOk, cs.ErrCheckCommaOk)
+
+	for _, list := range []struct {
+		dst *[]string
+		src []string
+	}{
+		{&cfg.LockMethods, cs.LockMethods},
+		{&cfg.UnlockMethods, cs.UnlockMethods},
+		{&cfg.LockTypes, cs.LockTypes},
+	} {
+		if list.src != nil {
+			*list.dst = list.src
+		}
+	}
+
+	if cs.Default != nil {
+		checks, err := NewCheckSet(*cs.Default, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		cfg.Checks = checks
+	}
+
+	if err := cfg.Checks.update(cs.Enable, cs.Disable); err != nil {
+		return err
+	}
+
+	severities, err := NewSeverities(cs.Severity)
+	if err != nil {
+		return err
+	}
+
+	cfg.setSeverities(severities)
+
+	return nil
+}
+
+// ApplyFor applies the settings and all overrides matching filename to the
+// configuration.
+func (cf *ConfigFile) ApplyFor(cfg *Configuration, filename string) error {
+	if err := cf.Apply(cfg); err != nil {
+		return err
+	}
+
+	for _, i := range cf.matchingOverrides(filename) {
+		if err := cf.Overrides[i].Apply(cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchingOverrides returns the index of all overrides matching filename.
+func (cf *ConfigFile) matchingOverrides(filename string) []int {
+	if len(cf.Overrides) == 0 {
+		return nil
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(cf.Path))
+	if err != nil {
+		return nil
+	}
+
+	filename, err = filepath.Abs(filename)
+	if err != nil {
+		return nil
+	}
+
+	rel, err := filepath.Rel(dir, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	var matching []int
+
+	for i, override := range cf.Overrides {
+		if slices.ContainsFunc(override.Paths, func(pattern string) bool {
+			return matchGlob(pattern, rel)
+		}) {
+			matching = append(matching, i)
+		}
+	}
+
+	return matching
+}
+
+// matchGlob reports whether the slash separated name matches pattern. Each
+// element is matched with path.Match except `**` which matches zero or more
+// elements.
+func matchGlob(pattern, name string) bool {
+	return matchGlobElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobElements(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := range len(name) + 1 {
+				if matchGlobElements(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+func validateGlob(pattern string) error {
+	for element := range strings.SplitSeq(pattern, "/") {
+		if _, err := path.Match(element, ""); err != nil {
+			return fmt.Errorf("invalid pattern '%s'", pattern)
+		}
+	}
+
+	return nil
+}
+
+func setIfNotNil[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func configBool(value any) (*bool, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("expected boolean, got '%v'", value)
+	}
+
+	return &b, nil
+}
+
+func configInt(value any) (*int, error) {
+	var i int
+
+	switch v := value.(type) {
+	case int:
+		i = v
+	case int64:
+		i = int(v)
+	case uint64:
+		i = int(v)
+	default:
+		return nil, fmt.Errorf("expected integer, got '%v'", value)
+	}
+
+	return &i, nil
+}
+
+func configString(value any) (*string, error) {
+	// An empty YAML value is the same as the default value.
+	if value == nil {
+		s := ""
+		return &s, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string, got '%v'", value)
+	}
+
+	return &s, nil
+}
+
+func configStrings(value any) ([]string, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected list of strings, got '%v'", value)
+	}
+
+	strs := make([]string, 0, len(values))
+
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected list of strings, got '%v'", v)
+		}
+
+		strs = append(strs, s)
+	}
+
+	return strs, nil
+}
+
+// configSeverities converts a map of check to severity to the same format as
+// the `-severity` flag, e.g. `after-block=warning`.
+func configSeverities(value any) ([]string, error) {
+	values, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected map of check to severity, got '%v'", value)
+	}
+
+	severities := make([]string, 0, len(values))
+
+	for check, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected severity for '%s', got '%v'", check, v)
+		}
+
+		severities = append(severities, check+"="+s)
+	}
+
+	slices.Sort(severities)
+
+	return severities, nil
+}
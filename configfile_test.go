@@ -0,0 +1,387 @@
+package wsl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name                string
+		filename            string
+		content             string
+		expected            func(*Configuration)
+		expectedErrContains string
+	}{
+		{
+			name:     "yaml",
+			filename: ".wsl.yml",
+			content: `
+allow-whole-block: true
+branch-max-lines: 5
+default: none
+enable:
+  - if
+  - after-block
+severity:
+  after-block: warning
+lock-methods: [Lock, Acquire]
+lock-types: [sync.Locker]
+`,
+			expected: func(cfg *Configuration) {
+				cfg.LockMethods = []string{"Lock", "Acquire"}
+				cfg.LockTypes = []string{"sync.Locker"}
+				cfg.AllowWholeBlock = true
+				cfg.BranchMaxLines = 5
+				cfg.Checks = CheckSet{CheckIf: {}, CheckAfterBlock: {}}
+				cfg.Severities = Severities{CheckAfterBlock: SeverityWarning}
+			},
+		},
+		{
+			name:     "toml",
+			filename: ".wsl.toml",
+			content: `
+cuddle-max-statements = 2
+disable = ["err"]
+
+[severity]
+return = "off"
+`,
+			expected: func(cfg *Configuration) {
+				cfg.CuddleMaxStatements = 2
+				cfg.Checks.Remove(CheckErr)
+				cfg.Checks.Remove(CheckReturn)
+				cfg.Severities = Severities{CheckReturn: SeverityOff}
+			},
+		},
+		{
+			name:                "unknown key",
+			filename:            ".wsl.yml",
+			content:             "allow-everything: true\n",
+			expectedErrContains: "unknown key 'allow-everything'",
+		},
+		{
+			name:                "invalid type",
+			filename:            ".wsl.toml",
+			content:             "branch-max-lines = \"two\"\n",
+			expectedErrContains: "key 'branch-max-lines': expected integer",
+		},
+		{
+			name:                "invalid check",
+			filename:            ".wsl.yml",
+			content:             "enable: [if, iff]\n",
+			expectedErrContains: "key 'enable': invalid check 'iff'",
+		},
+		{
+			name:                "invalid preset",
+			filename:            ".wsl.yml",
+			content:             "default: some\n",
+			expectedErrContains: "key 'default': invalid preset",
+		},
+		{
+			name:                "invalid lock type",
+			filename:            ".wsl.yml",
+			content:             "lock-types: [Locker]\n",
+			expectedErrContains: "key 'lock-types': invalid lock type 'Locker'",
+		},
+		{
+			name:                "invalid severity",
+			filename:            ".wsl.yml",
+			content:             "severity:\n  if: fatal\n",
+			expectedErrContains: "key 'severity': invalid severity 'fatal'",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), tc.filename)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			cf, err := LoadConfigFile(path)
+			if tc.expectedErrContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErrContains)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			cfg := NewConfig()
+			require.NoError(t, cf.Apply(cfg))
+
+			expected := NewConfig()
+			tc.expected(expected)
+
+			assert.Equal(t, expected, cfg)
+		})
+	}
+}
+
+func TestLoadConfigFileOverrides(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name                string
+		content             string
+		expectedErrContains string
+	}{
+		{
+			name:                "missing paths",
+			content:             "overrides:\n  - disable: [err]\n",
+			expectedErrContains: "key 'overrides[0].paths': expected non empty list of strings",
+		},
+		{
+			name:                "invalid pattern",
+			content:             "overrides:\n  - paths: ['[a-']\n",
+			expectedErrContains: "key 'overrides[0].paths': invalid pattern '[a-'",
+		},
+		{
+			name:                "invalid check",
+			content:             "overrides:\n  - paths: ['**']\n  - paths: ['**']\n    enable: [iff]\n",
+			expectedErrContains: "key 'overrides[1].enable': invalid check 'iff'",
+		},
+		{
+			name:                "unknown key",
+			content:             "overrides:\n  - paths: ['**']\n    overrides: []\n",
+			expectedErrContains: "unknown key 'overrides[0].overrides'",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), ".wsl.yml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			_, err := LoadConfigFile(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErrContains)
+		})
+	}
+}
+
+func TestConfigFileApplyFor(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".wsl.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+branch-max-lines = 3
+
+[[overrides]]
+paths = ["internal/**"]
+default = "all"
+
+[[overrides]]
+paths = ["**/*_test.go", "testdata/**"]
+branch-max-lines = 5
+disable = ["err", "assign-exclusive"]
+`), 0o600))
+
+	cf, err := LoadConfigFile(path)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		filename string
+		expected func(*Configuration)
+	}{
+		{
+			filename: "main.go",
+			expected: func(cfg *Configuration) {
+				cfg.BranchMaxLines = 3
+			},
+		},
+		{
+			filename: "internal/a/a.go",
+			expected: func(cfg *Configuration) {
+				cfg.BranchMaxLines = 3
+				cfg.Checks = AllChecks()
+			},
+		},
+		{
+			filename: "internal/a/a_test.go",
+			expected: func(cfg *Configuration) {
+				cfg.BranchMaxLines = 5
+				cfg.Checks = AllChecks()
+				cfg.Checks.Remove(CheckErr)
+				cfg.Checks.Remove(CheckAssignExclusive)
+			},
+		},
+		{
+			filename: "main_test.go",
+			expected: func(cfg *Configuration) {
+				cfg.BranchMaxLines = 5
+				cfg.Checks.Remove(CheckErr)
+			},
+		},
+		{
+			filename: "testdata/src/a.go",
+			expected: func(cfg *Configuration) {
+				cfg.BranchMaxLines = 5
+				cfg.Checks.Remove(CheckErr)
+			},
+		},
+		{
+			filename: "../outside/internal/a.go",
+			expected: func(cfg *Configuration) {
+				cfg.BranchMaxLines = 3
+			},
+		},
+	} {
+		t.Run(tc.filename, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewConfig()
+			require.NoError(t, cf.ApplyFor(cfg, filepath.Join(dir, filepath.FromSlash(tc.filename))))
+
+			expected := NewConfig()
+			tc.expected(expected)
+
+			assert.Equal(t, expected, cfg)
+		})
+	}
+}
+
+func TestConfigFileApplyForSeverity(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".wsl.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+severity:
+  err: off
+  if: off
+
+overrides:
+  - paths: ["legacy/**"]
+    severity:
+      err: warning
+`), 0o600))
+
+	cf, err := LoadConfigFile(path)
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	require.NoError(t, cf.ApplyFor(cfg, filepath.Join(dir, "main.go")))
+
+	assert.NotContains(t, cfg.Checks, CheckErr)
+	assert.NotContains(t, cfg.Checks, CheckIf)
+
+	cfg = NewConfig()
+	require.NoError(t, cf.ApplyFor(cfg, filepath.Join(dir, "legacy", "a.go")))
+
+	assert.Contains(t, cfg.Checks, CheckErr)
+	assert.NotContains(t, cfg.Checks, CheckIf)
+	assert.Equal(t, Severities{CheckErr: SeverityWarning, CheckIf: SeverityOff}, cfg.Severities)
+}
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"**", "a/b/c.go", true},
+		{"*.go", "a.go", true},
+		{"*.go", "a/b.go", false},
+		{"**/*_test.go", "a_test.go", true},
+		{"**/*_test.go", "a/b/c_test.go", true},
+		{"**/*_test.go", "a/b/c.go", false},
+		{"internal/**", "internal/a.go", true},
+		{"internal/**", "pkg/internal/a.go", false},
+		{"**/testdata/**", "pkg/testdata/src/a.go", true},
+		{"a/**/b/*.go", "a/b/c.go", true},
+		{"a/**/b/*.go", "a/x/y/b/c.go", true},
+		{"a/**/b/*.go", "a/x/y/c.go", false},
+	} {
+		assert.Equal(t, tc.expected, matchGlob(tc.pattern, tc.name), "%s ~ %s", tc.pattern, tc.name)
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0o750))
+
+	path, err := FindConfigFile(nested)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	configPath := filepath.Join(root, "a", ".wsl.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte(""), 0o600))
+
+	path, err = FindConfigFile(nested)
+	require.NoError(t, err)
+	assert.Equal(t, configPath, path)
+
+	// YAML has precedence in the same directory.
+	yamlPath := filepath.Join(root, "a", ".wsl.yml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(""), 0o600))
+
+	path, err = FindConfigFile(nested)
+	require.NoError(t, err)
+	assert.Equal(t, yamlPath, path)
+}
+
+func TestFlagsOverrideConfigFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".wsl.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+branch-max-lines: 5
+case-max-lines: 3
+disable: [if]
+severity:
+  for: warning
+  range: info
+  assign: off
+`), 0o600))
+
+	cf, err := LoadConfigFile(path)
+	require.NoError(t, err)
+
+	wa := &wslAnalyzer{}
+	flags := wa.flags()
+	require.NoError(t, flags.Parse([]string{
+		"-branch-max-lines", "10",
+		"-enable", "if,after-block",
+		"-disable", "range",
+		"-severity", "for=error,assign=warning",
+	}))
+
+	cfg, err := wa.resolveConfig(cf, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, cfg.BranchMaxLines)
+	assert.Equal(t, 3, cfg.CaseMaxLines)
+	assert.Contains(t, cfg.Checks, CheckIf)
+	assert.Contains(t, cfg.Checks, CheckAfterBlock)
+	assert.NotContains(t, cfg.Checks, CheckRange)
+	assert.Equal(t, SeverityError, cfg.Severities.Get(CheckFor))
+	assert.Equal(t, SeverityInfo, cfg.Severities.Get(CheckRange))
+
+	// A check turned off in the file is enabled again by a severity flag.
+	assert.Contains(t, cfg.Checks, CheckAssign)
+	assert.Equal(t, SeverityWarning, cfg.Severities.Get(CheckAssign))
+
+	// A preset from the flags replaces the checks from the file.
+	wa = &wslAnalyzer{}
+	flags = wa.flags()
+	require.NoError(t, flags.Parse([]string{"-default", "none", "-enable", "err"}))
+
+	cfg, err = wa.resolveConfig(cf, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, CheckSet{CheckErr: {}}, cfg.Checks)
+	assert.Equal(t, 5, cfg.BranchMaxLines)
+}
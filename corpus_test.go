@@ -0,0 +1,104 @@
+package wsl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:gochecknoglobals // Test flag.
+var update = flag.Bool("update", false, "update golden files")
+
+// TestCorpus runs all checks over a snapshot of real-world code and compares
+// the number of issues per check and the fixed source with a golden summary to
+// catch unintended changes in behavior. The files are analyzed without type
+// information since they're only a part of their packages.
+func TestCorpus(t *testing.T) {
+	t.Parallel()
+
+	const (
+		corpusDir   = "testdata/corpus"
+		summaryFile = "testdata/corpus/summary.golden"
+	)
+
+	cfg := NewConfig()
+	cfg.Checks = AllChecks()
+
+	var sb strings.Builder
+
+	err := filepath.WalkDir(corpusDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".go" {
+			return err
+		}
+
+		name, _ := filepath.Rel(corpusDir, path)
+		sb.WriteString(corpusSummary(t, filepath.ToSlash(name), path, cfg))
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	if *update {
+		require.NoError(t, os.WriteFile(summaryFile, []byte(sb.String()), 0o600))
+		return
+	}
+
+	want, err := os.ReadFile(summaryFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(want), sb.String(), "run with -update if the change is expected")
+}
+
+// corpusSummary returns the number of issues per check and fixes for the file
+// together with a hash of the source with all fixes applied.
+func corpusSummary(t *testing.T, name, path string, cfg *Configuration) string {
+	t.Helper()
+
+	src, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	require.NoError(t, err)
+
+	var (
+		issues = Lint(fset, file, nil, cfg)
+		checks = map[CheckType]int{}
+		edits  []TextEdit
+	)
+
+	for _, issue := range issues {
+		checks[issue.CheckType]++
+		edits = append(edits, issue.Edits...)
+	}
+
+	fixed := applyTextEdits(fset.File(file.Pos()), src, edits)
+
+	_, err = parser.ParseFile(token.NewFileSet(), path, fixed, parser.ParseComments)
+	require.NoError(t, err, "fixed source doesn't parse")
+
+	hash := sha256.Sum256(fixed)
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s: %d issues, %d fixes, %s\n", name, len(issues), len(edits), hex.EncodeToString(hash[:8]))
+
+	for _, check := range slices.Sorted(maps.Keys(checks)) {
+		fmt.Fprintf(&sb, "\t%s: %d\n", check, checks[check])
+	}
+
+	return sb.String()
+}
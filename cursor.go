@@ -14,8 +14,9 @@ type Cursor struct {
 	// rbraceLine is the source line of the enclosing block's closing brace.
 	// It is used to avoid false positives when checking for trailing comments:
 	// an inline comment that sits on the same line as the closing brace belongs
-	// to the brace itself, not to the last statement inside the block.
-	// Zero means the enclosing block boundary is unknown (e.g. case clauses).
+	// to the brace itself, not to the last statement inside the block. For case
+	// clauses it's the line of the next clause or the closing brace. Zero means
+	// the enclosing block boundary is unknown.
 	rbraceLine int
 }
 
@@ -0,0 +1,276 @@
+package wsl
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+const (
+	directivePrefix        = "//wsl:"
+	directiveIgnore        = "ignore"
+	directiveFileIgnore    = "file-ignore"
+	messageUnusedDirective = "unused directive"
+)
+
+// directive is a parsed `//wsl:ignore` or `//wsl:file-ignore` comment. Issues
+// reported between `start` and `end` for any of the checks (or all checks if
+// none are listed) are dropped.
+type directive struct {
+	comment *ast.Comment
+	checks  CheckSet
+	start   token.Pos
+	end     token.Pos
+	used    bool
+
+	// isTrailing is true if the directive is placed after code on the same
+	// line. We only suggest removing unused directives on their own line since
+	// we don't know how much whitespace precedes a trailing one.
+	isTrailing bool
+}
+
+func (d *directive) matches(report token.Pos, check CheckType) bool {
+	if report < d.start || report > d.end {
+		return false
+	}
+
+	if len(d.checks) == 0 {
+		return true
+	}
+
+	_, ok := d.checks[check]
+
+	return ok
+}
+
+// parseDirective parses the comment and returns the directive name and the
+// list of checks it applies to. If the comment isn't a directive, ok is false.
+// Invalid check names are kept as CheckInvalid so the directive never matches
+// anything and can be reported as unused.
+//
+//	//wsl:ignore
+//	//wsl:ignore after-block,err
+//	//wsl:ignore err // Explanation why
+func parseDirective(text string) (string, CheckSet, bool) {
+	rest, ok := strings.CutPrefix(text, directivePrefix)
+	if !ok {
+		return "", nil, false
+	}
+
+	// Anything after a nested comment is an explanation.
+	rest, _, _ = strings.Cut(rest, "//")
+	fields := strings.Fields(rest)
+
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+
+	name := fields[0]
+	if name != directiveIgnore && name != directiveFileIgnore {
+		return "", nil, false
+	}
+
+	checks := CheckSet{}
+
+	for _, field := range fields[1:] {
+		for s := range strings.SplitSeq(field, ",") {
+			if s == "" {
+				continue
+			}
+
+			check, _ := reportedCheckFromString(s)
+			checks.Add(check)
+		}
+	}
+
+	return name, checks, true
+}
+
+// collectDirectives finds all directives in the file and resolves the range
+// each one of them covers.
+//
+// A `//wsl:file-ignore` covers the whole file. A `//wsl:ignore` on its own
+// line covers the node starting on the line below it, a trailing
+// `//wsl:ignore` covers the node starting on the same line. If no node starts
+// on that line, only the line itself is covered. Since doc comments are placed
+// above declarations, this makes a directive in a function doc cover the whole
+// function.
+func (w *WSL) collectDirectives() []*directive {
+	var directives []*directive
+
+	for _, cg := range w.comments.groups {
+		for _, c := range cg.List {
+			name, checks, ok := parseDirective(c.Text)
+			if !ok {
+				continue
+			}
+
+			d := &directive{
+				comment: c,
+				checks:  checks,
+			}
+
+			if name == directiveFileIgnore {
+				d.start, d.end = w.file.FileStart, w.file.FileEnd
+			}
+
+			directives = append(directives, d)
+		}
+	}
+
+	if len(directives) == 0 {
+		return nil
+	}
+
+	var (
+		// The outermost node starting on each line.
+		nodeOnLine = map[int]ast.Node{}
+		// The first position on each line that holds code.
+		codeStartOnLine = map[int]token.Pos{}
+	)
+
+	ast.Inspect(w.file, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.File, *ast.CommentGroup, *ast.Comment:
+			return true
+		}
+
+		line := w.lineFor(n.Pos())
+		if _, ok := nodeOnLine[line]; !ok {
+			nodeOnLine[line] = n
+			codeStartOnLine[line] = n.Pos()
+		}
+
+		// Closing braces and parentheses are not the start of any node so we
+		// record the end of nodes as well to detect code on the line.
+		endLine := w.lineFor(n.End())
+		if start, ok := codeStartOnLine[endLine]; !ok || n.End()-1 < start {
+			codeStartOnLine[endLine] = n.End() - 1
+		}
+
+		return true
+	})
+
+	file := w.fset.File(w.file.Pos())
+
+	for _, d := range directives {
+		commentLine := w.lineFor(d.comment.Pos())
+		codeStart, hasCode := codeStartOnLine[commentLine]
+		d.isTrailing = hasCode && codeStart < d.comment.Pos()
+
+		// Already resolved as a file directive.
+		if d.start.IsValid() {
+			continue
+		}
+
+		targetLine := commentLine
+		if !d.isTrailing {
+			targetLine = w.lineFor(w.commentGroupEnd(d.comment)) + 1
+		}
+
+		if targetLine > file.LineCount() {
+			d.start, d.end = d.comment.Pos(), d.comment.End()
+			continue
+		}
+
+		if node, ok := nodeOnLine[targetLine]; ok {
+			d.start, d.end = file.LineStart(targetLine), node.End()
+			continue
+		}
+
+		d.start = file.LineStart(targetLine)
+		d.end = d.start
+
+		if targetLine < file.LineCount() {
+			d.end = file.LineStart(targetLine+1) - 1
+		}
+	}
+
+	return directives
+}
+
+// commentGroupEnd returns the end of the comment group the comment belongs to
+// so a directive placed above other comment lines still covers the node below
+// the whole group.
+func (w *WSL) commentGroupEnd(c *ast.Comment) token.Pos {
+	if cg := w.comments.enclosing(c.Pos()); cg != nil {
+		return cg.End()
+	}
+
+	return c.End()
+}
+
+// applyDirectives drops all issues covered by a directive and, if enabled,
+// reports directives that didn't suppress anything.
+func (w *WSL) applyDirectives() {
+	directives := w.collectDirectives()
+
+	for pos, iss := range w.issues {
+		for _, d := range directives {
+			if d.matches(pos, iss.checkType) {
+				d.used = true
+
+				delete(w.issues, pos)
+			}
+		}
+	}
+
+	if _, ok := w.config.Checks[CheckUnusedDirective]; !ok {
+		return
+	}
+
+	file := w.fset.File(w.file.Pos())
+
+	for _, d := range directives {
+		if d.used {
+			continue
+		}
+
+		message := fmt.Sprintf("%s (%s)", messageUnusedDirective, CheckUnusedDirective)
+
+		if d.isTrailing {
+			w.issues[d.comment.Pos()] = issue{
+				message:   message,
+				reason:    ReasonUnusedDirective,
+				checkType: CheckUnusedDirective,
+				fixRanges: []fixRange{},
+			}
+
+			continue
+		}
+
+		line := w.lineFor(d.comment.Pos())
+		removeStart, removeEnd := file.LineStart(line), d.comment.End()
+
+		if line < file.LineCount() {
+			removeEnd = file.LineStart(line + 1)
+		}
+
+		// If the directive is first in the file or after an empty line, an
+		// empty line after it is removed as well to not leave two empty lines.
+		if (line == 1 || isEmptyLine(file, line-1)) && isEmptyLine(file, line+1) {
+			removeEnd = file.LineStart(line + 2)
+		}
+
+		w.addErrorWithMessageAndFix(
+			d.comment.Pos(),
+			removeStart,
+			removeEnd,
+			message,
+			[]byte{},
+			ReasonUnusedDirective,
+			CheckUnusedDirective,
+		)
+	}
+}
+
+// isEmptyLine reports if the line only holds a newline. The last line is never
+// considered empty since it has no newline.
+func isEmptyLine(file *token.File, line int) bool {
+	if line < 1 || line >= file.LineCount() {
+		return false
+	}
+
+	return file.LineStart(line+1)-file.LineStart(line) == 1
+}
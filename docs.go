@@ -0,0 +1,75 @@
+package wsl
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+//go:embed CHECKS.md
+var checksDoc string
+
+// checkDescriptions maps each section in CHECKS.md to its first paragraph of
+// text. Notes, tables and code blocks are skipped.
+var checkDescriptions = sync.OnceValue(func() map[string]string {
+	descriptions := map[string]string{}
+
+	var (
+		section   string
+		paragraph []string
+		inCode    bool
+		skip      bool
+	)
+
+	for line := range strings.Lines(checksDoc) {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+
+		if inCode {
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(line, "### `"); ok {
+			section = strings.TrimSuffix(name, "`")
+			paragraph = nil
+			skip = false
+
+			continue
+		}
+
+		if section == "" {
+			continue
+		}
+
+		if line == "" {
+			if len(paragraph) > 0 {
+				descriptions[section] = strings.Join(paragraph, " ")
+				section = ""
+			}
+
+			skip = false
+
+			continue
+		}
+
+		if len(paragraph) == 0 && (strings.HasPrefix(line, ">") || strings.HasPrefix(line, "<") || strings.HasPrefix(line, "[")) {
+			skip = true
+		}
+
+		if !skip {
+			paragraph = append(paragraph, line)
+		}
+	}
+
+	return descriptions
+})
+
+// Description returns a short description of the check, the first paragraph
+// of its section in CHECKS.md.
+func (c CheckType) Description() string {
+	return checkDescriptions()[c.docSection()]
+}
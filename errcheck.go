@@ -0,0 +1,183 @@
+package wsl
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// errCheckIdent returns the identifier checked if stmt is an error check, nil
+// otherwise. Besides comparing an error with `nil` this includes the checks
+// enabled in the configuration, e.g. `errors.Is` calls or comma-ok checks of
+// the variable assigned in previous.
+func (w *WSL) errCheckIdent(stmt, previous ast.Node) *ast.Ident {
+	if ident := w.isErrNotNilCheck(stmt); ident != nil {
+		return ident
+	}
+
+	if w.config.ErrCheckCommaOk {
+		return w.isCommaOkCheck(stmt, previous)
+	}
+
+	return nil
+}
+
+// isErrNotNilCheck returns the error identifier if stmt is an `if err != nil`
+// or `if err == nil` check without an init statement, nil otherwise. If enabled
+// in the configuration this also includes fields (`if res.Err != nil`) and
+// `errors.Is` or `errors.As` calls.
+func (w *WSL) isErrNotNilCheck(stmt ast.Node) *ast.Ident {
+	ifStmt, ok := stmt.(*ast.IfStmt)
+	if !ok {
+		return nil
+	}
+
+	// If the error checking has an init condition (e.g. if err := f();) we
+	// don't consider it an error check since the error is assigned on this row.
+	if ifStmt.Init != nil {
+		return nil
+	}
+
+	cond := ifStmt.Cond
+
+	// Allow negating `errors.Is` and `errors.As`.
+	if unary, ok := cond.(*ast.UnaryExpr); ok && unary.Op == token.NOT {
+		cond = unary.X
+	}
+
+	switch c := cond.(type) {
+	case *ast.BinaryExpr:
+		return w.isNilComparison(c)
+	case *ast.CallExpr:
+		if !w.config.ErrCheckIsAs || !w.isErrorsIsOrAs(c) || len(c.Args) == 0 {
+			return nil
+		}
+
+		return w.errIdent(c.Args[0])
+	default:
+		return nil
+	}
+}
+
+// isNilComparison returns the error identifier if expr is `err != nil` or
+// `err == nil`.
+func (w *WSL) isNilComparison(expr *ast.BinaryExpr) *ast.Ident {
+	// We must do not equal or equal comparison (!= or ==)
+	if expr.Op != token.NEQ && expr.Op != token.EQL {
+		return nil
+	}
+
+	yIdent, ok := expr.Y.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	// Y is not compared with `nil`
+	if yIdent.Name != "nil" {
+		return nil
+	}
+
+	return w.errIdent(expr.X)
+}
+
+// errIdent returns the identifier for expr if it's an error. If selectors are
+// enabled in the configuration, the identifier for a field like `res.Err` is
+// the root of the selector, `res`.
+func (w *WSL) errIdent(expr ast.Expr) *ast.Ident {
+	var ident *ast.Ident
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		if !w.config.ErrCheckSelector {
+			return nil
+		}
+
+		ident = rootIdent(e)
+	}
+
+	// X is not an error so it's not error checking
+	if ident == nil || !w.implementsErr(expr) {
+		return nil
+	}
+
+	return ident
+}
+
+// isErrorsIsOrAs checks if call is a call to `errors.Is` or `errors.As`.
+func (w *WSL) isErrorsIsOrAs(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || (sel.Sel.Name != "Is" && sel.Sel.Name != "As") {
+		return false
+	}
+
+	pkgIdent, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	if w.typeInfo == nil {
+		return pkgIdent.Name == "errors" && w.isImportName("errors")
+	}
+
+	pkgName, ok := w.typeInfo.Uses[pkgIdent].(*types.PkgName)
+
+	return ok && pkgName.Imported().Path() == "errors"
+}
+
+// isCommaOkCheck returns the identifier for `ok` if stmt is `if !ok` or `if ok`
+// and previous is a comma-ok assignment of `ok`, e.g. `v, ok := m[k]`.
+func (w *WSL) isCommaOkCheck(stmt, previous ast.Node) *ast.Ident {
+	ifStmt, ok := stmt.(*ast.IfStmt)
+	if !ok || ifStmt.Init != nil {
+		return nil
+	}
+
+	cond := ifStmt.Cond
+	if unary, ok := cond.(*ast.UnaryExpr); ok && unary.Op == token.NOT {
+		cond = unary.X
+	}
+
+	okIdent, ok := cond.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	assign, ok := previous.(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return nil
+	}
+
+	if lhs, ok := assign.Lhs[1].(*ast.Ident); !ok || lhs.Name != okIdent.Name {
+		return nil
+	}
+
+	switch rhs := ast.Unparen(assign.Rhs[0]).(type) {
+	case *ast.IndexExpr, *ast.TypeAssertExpr:
+		return okIdent
+	case *ast.UnaryExpr:
+		if rhs.Op == token.ARROW {
+			return okIdent
+		}
+	}
+
+	return nil
+}
+
+// rootIdent returns the identifier at the root of a selector chain, e.g. `a`
+// for `a.b.c`.
+func rootIdent(expr ast.Expr) *ast.Ident {
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package wsl
+
+import (
+	"go/ast"
+)
+
+// checkFieldList checks the whitespace inside struct and interface types.
+// Leading and trailing empty lines are not allowed and, if enabled, embedded
+// fields must be separated from named fields by an empty line.
+func (w *WSL) checkFieldList(fields *ast.FieldList) {
+	if fields == nil || len(fields.List) == 0 || !fields.Opening.IsValid() {
+		return
+	}
+
+	w.checkLeadingNewlineBefore(fields.Opening, fields.List[0].Pos(), CheckTypeWhitespace)
+	w.checkTrailingNewlineAfter(fields.List[len(fields.List)-1].End(), fields.Closing, CheckTypeWhitespace)
+
+	if _, ok := w.config.Checks[CheckEmbeddedField]; !ok {
+		return
+	}
+
+	for i := 1; i < len(fields.List); i++ {
+		previous, current := fields.List[i-1], fields.List[i]
+		if isEmbeddedField(previous) == isEmbeddedField(current) {
+			continue
+		}
+
+		w.checkNewlineBetween(previous, current, CheckEmbeddedField)
+	}
+}
+
+// isEmbeddedField returns true if the field is an embedded field in a struct
+// or an embedded type in an interface.
+func isEmbeddedField(field *ast.Field) bool {
+	return len(field.Names) == 0
+}
@@ -0,0 +1,148 @@
+package wsl
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+)
+
+var errLostComments = errors.New("comments are lost")
+
+// droppedFix is a fix removed from an issue since applying it would result in
+// invalid code.
+type droppedFix struct {
+	issue Issue
+	err   error
+}
+
+// validateFixes applies the edits from all issues to a copy of the source and
+// makes sure the result still parses and keeps all comments. If it doesn't,
+// the issues are bisected to find the ones breaking the file and their edits
+// are removed. The source must be the source the file was parsed from.
+//
+// The result is only parsed, not type checked. Fixes resulting in valid syntax
+// that doesn't compile, e.g. by moving a declaration below its first use, are
+// not detected.
+func validateFixes(fset *token.FileSet, file *ast.File, src []byte, issues []Issue) []droppedFix {
+	tokenFile := fset.File(file.Pos())
+	if tokenFile == nil || tokenFile.Size() != len(src) {
+		return nil
+	}
+
+	comments := commentTexts(file)
+
+	validate := func(edits []TextEdit) error {
+		// Edits are sorted when applied so we use a copy.
+		fixed := applyTextEdits(tokenFile, src, slices.Clone(edits))
+
+		fixedFile, err := parser.ParseFile(token.NewFileSet(), tokenFile.Name(), fixed, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+
+		if !slices.Equal(comments, commentTexts(fixedFile)) {
+			return errLostComments
+		}
+
+		return nil
+	}
+
+	var (
+		edits     []TextEdit
+		withEdits []int
+	)
+
+	for i, issue := range issues {
+		if len(issue.Edits) == 0 {
+			continue
+		}
+
+		edits = append(edits, issue.Edits...)
+		withEdits = append(withEdits, i)
+	}
+
+	if len(edits) == 0 {
+		return nil
+	}
+
+	err := validate(edits)
+	if err == nil {
+		return nil
+	}
+
+	// Files with syntax errors are analyzed as well, we can only validate that
+	// fixes don't make things worse if the file is valid to begin with.
+	if validate(nil) != nil {
+		return nil
+	}
+
+	var dropped []droppedFix
+
+	appendEdits := func(edits []TextEdit, indices []int) []TextEdit {
+		edits = slices.Clone(edits)
+		for _, i := range indices {
+			edits = append(edits, issues[i].Edits...)
+		}
+
+		return edits
+	}
+
+	// bisect finds the issues breaking the file among the issues failing with
+	// err when combined with the valid edits. The fixes for those issues are
+	// dropped and the valid edits including the fixes for the remaining issues
+	// are returned.
+	var bisect func(valid []TextEdit, failing []int, err error) []TextEdit
+
+	bisect = func(valid []TextEdit, failing []int, err error) []TextEdit {
+		if len(failing) == 1 {
+			dropped = append(dropped, droppedFix{issue: issues[failing[0]], err: err})
+			issues[failing[0]].Edits = nil
+
+			return valid
+		}
+
+		first, second := failing[:len(failing)/2], failing[len(failing)/2:]
+
+		candidate := appendEdits(valid, first)
+
+		firstErr := validate(candidate)
+		if firstErr == nil {
+			// The second half combined with the candidate is all the edits we
+			// already know fails with err.
+			return bisect(candidate, second, err)
+		}
+
+		valid = bisect(valid, first, firstErr)
+
+		candidate = appendEdits(valid, second)
+		if secondErr := validate(candidate); secondErr != nil {
+			return bisect(valid, second, secondErr)
+		}
+
+		return candidate
+	}
+
+	bisect(nil, withEdits, err)
+
+	return dropped
+}
+
+// commentTexts returns the text of all comments in the file except directives
+// since unused directives are removed by their fix.
+func commentTexts(file *ast.File) []string {
+	var texts []string
+
+	for _, cg := range file.Comments {
+		for _, c := range cg.List {
+			if _, _, ok := parseDirective(c.Text); ok {
+				continue
+			}
+
+			texts = append(texts, c.Text)
+		}
+	}
+
+	return texts
+}
@@ -0,0 +1,156 @@
+package wsl
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFixes(t *testing.T) {
+	t.Parallel()
+
+	src := `package main
+
+func main() {
+	a := 1
+	// Comment
+	_ = a
+	//wsl:ignore
+	_ = a
+}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	require.NoError(t, err)
+
+	tokenFile := fset.File(file.Pos())
+	pos := func(s string) token.Pos {
+		return tokenFile.Pos(strings.Index(src, s))
+	}
+
+	var (
+		insertNewline = TextEdit{Pos: pos("\t// Comment"), End: pos("\t// Comment"), NewText: []byte("\n")}
+		removeBrace   = TextEdit{Pos: pos("{"), End: pos("{") + 1, NewText: []byte{}}
+		removeComment = TextEdit{Pos: pos("\t// Comment"), End: pos("\t_ = a"), NewText: []byte{}}
+		removeIgnore  = TextEdit{Pos: pos("\t//wsl:ignore"), End: pos("\t_ = a\n}"), NewText: []byte{}}
+	)
+
+	newIssues := func(edits ...TextEdit) []Issue {
+		issues := []Issue{}
+
+		for _, e := range edits {
+			issues = append(issues, Issue{Pos: e.Pos, CheckType: CheckAssign, Edits: []TextEdit{e}})
+		}
+
+		return issues
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		issues := newIssues(insertNewline, removeIgnore)
+		issues = append(issues, Issue{Pos: pos("_ = a")})
+
+		assert.Empty(t, validateFixes(fset, file, []byte(src), issues))
+		assert.Equal(t, []TextEdit{insertNewline}, issues[0].Edits)
+		assert.Equal(t, []TextEdit{removeIgnore}, issues[1].Edits)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		issues := newIssues(removeBrace, insertNewline, removeComment)
+		dropped := validateFixes(fset, file, []byte(src), issues)
+
+		require.Len(t, dropped, 2)
+		assert.Equal(t, removeBrace.Pos, dropped[0].issue.Pos)
+		require.Error(t, dropped[0].err)
+		assert.Equal(t, removeComment.Pos, dropped[1].issue.Pos)
+		require.ErrorIs(t, dropped[1].err, errLostComments)
+
+		assert.Empty(t, issues[0].Edits)
+		assert.Equal(t, []TextEdit{insertNewline}, issues[1].Edits)
+		assert.Empty(t, issues[2].Edits)
+	})
+
+	t.Run("source doesn't match", func(t *testing.T) {
+		t.Parallel()
+
+		issues := newIssues(removeBrace)
+
+		assert.Empty(t, validateFixes(fset, file, []byte(src+"\n"), issues))
+		assert.Len(t, issues[0].Edits, 1)
+	})
+}
+
+func TestValidateFixesInvalidSource(t *testing.T) {
+	t.Parallel()
+
+	src := "package main\n\nfunc main() {\n\ta := 1\n"
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	require.Error(t, err)
+
+	pos := fset.File(file.Pos()).Pos(strings.Index(src, "\ta"))
+	issues := []Issue{{Pos: pos, Edits: []TextEdit{{Pos: pos, End: pos, NewText: []byte("\n")}}}}
+
+	// We can't tell if a fix is valid if the source isn't.
+	assert.Empty(t, validateFixes(fset, file, []byte(src), issues))
+	assert.Len(t, issues[0].Edits, 1)
+}
+
+func TestValidateFixesBisect(t *testing.T) {
+	t.Parallel()
+
+	var sb strings.Builder
+
+	sb.WriteString("package main\n\nfunc main() {\n")
+
+	for range 100 {
+		sb.WriteString("\t_ = 1\n")
+	}
+
+	sb.WriteString("}\n")
+
+	src := sb.String()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	require.NoError(t, err)
+
+	tokenFile := fset.File(file.Pos())
+
+	var issues []Issue
+
+	for i := range 100 {
+		pos := tokenFile.LineStart(i + 5)
+		issues = append(issues, Issue{Pos: pos, Edits: []TextEdit{{Pos: pos, End: pos, NewText: []byte("\n")}}})
+	}
+
+	// Removing the `=` from two statements breaks the file.
+	for _, i := range []int{17, 70} {
+		pos := tokenFile.LineStart(i+4) + 3
+		issues[i].Edits = append(issues[i].Edits, TextEdit{Pos: pos, End: pos + 1, NewText: []byte{}})
+	}
+
+	dropped := validateFixes(fset, file, []byte(src), issues)
+
+	require.Len(t, dropped, 2)
+	assert.Equal(t, issues[17].Pos, dropped[0].issue.Pos)
+	assert.Equal(t, issues[70].Pos, dropped[1].issue.Pos)
+
+	for i, issue := range issues {
+		if i == 17 || i == 70 {
+			assert.Empty(t, issue.Edits)
+			continue
+		}
+
+		assert.Len(t, issue.Edits, 1)
+	}
+}
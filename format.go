@@ -0,0 +1,159 @@
+package wsl
+
+import (
+	"bytes"
+	"cmp"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"sync"
+)
+
+// maxFormatIterations is the max number of times fixes are applied to the
+// source. Applying a fix can result in new issues, e.g. when adding an empty
+// line before a statement, so we run until there's nothing left to fix.
+const maxFormatIterations = 10
+
+// formatFilename is the name used when parsing source without a filename.
+const formatFilename = "source.go"
+
+// FormatSource applies all fixes for the checks enabled in the configuration
+// to the Go source and returns the result. See FormatFile for details.
+func FormatSource(src []byte, cfg *Configuration) ([]byte, error) {
+	return FormatFile(formatFilename, src, cfg)
+}
+
+// FormatFile applies all fixes for the checks enabled in the configuration to
+// the Go source and returns the result. The filename is only used for error
+// messages. Fixes are applied until there's nothing left to fix.
+//
+// The source is type checked on its own so type information is best effort,
+// meaning that checks depending on types might find fewer issues than when
+// running the analyzer on the whole package. If SyntaxOnly is set, the source
+// isn't type checked at all.
+func FormatFile(filename string, src []byte, cfg *Configuration) ([]byte, error) {
+	for range maxFormatIterations {
+		fset := token.NewFileSet()
+
+		issues, err := LintSource(fset, filename, src, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		var edits []TextEdit
+		for _, issue := range issues {
+			edits = append(edits, issue.Edits...)
+		}
+
+		if len(edits) == 0 {
+			break
+		}
+
+		src = applyTextEdits(fset.File(edits[0].Pos), src, edits)
+	}
+
+	return src, nil
+}
+
+// LintSource parses the Go source, adds it to the file set and returns the
+// issues found. Like FormatFile, the source is type checked on its own unless
+// SyntaxOnly is set.
+func LintSource(fset *token.FileSet, filename string, src []byte, cfg *Configuration) ([]Issue, error) {
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		info *types.Info
+		pkg  *types.Package
+	)
+
+	if !cfg.SyntaxOnly {
+		info, pkg = typeCheckFile(fset, file)
+	}
+
+	return lint(fset, file, info, pkg, nil, cfg), nil
+}
+
+// applyTextEdits applies the edits to src. Identical edits are only applied
+// once and edits overlapping an already applied edit are skipped, they will be
+// found again in the next iteration if they're still needed.
+func applyTextEdits(file *token.File, src []byte, edits []TextEdit) []byte {
+	slices.SortStableFunc(edits, func(a, b TextEdit) int {
+		return cmp.Or(cmp.Compare(a.Pos, b.Pos), cmp.Compare(a.End, b.End))
+	})
+
+	var (
+		buf    bytes.Buffer
+		offset int
+		last   *TextEdit
+	)
+
+	for _, e := range edits {
+		if last != nil && last.Pos == e.Pos && last.End == e.End && bytes.Equal(last.NewText, e.NewText) {
+			continue
+		}
+
+		start, end := file.Offset(e.Pos), file.Offset(e.End)
+		if start < offset {
+			continue
+		}
+
+		buf.Write(src[offset:start])
+		buf.Write(e.NewText)
+
+		offset = end
+		last = &e
+	}
+
+	buf.Write(src[offset:])
+
+	return buf.Bytes()
+}
+
+// typeCheckFile type checks the file on its own. All errors are ignored since
+// we only have a single file and we use whatever type information we get.
+func typeCheckFile(fset *token.FileSet, file *ast.File) (*types.Info, *types.Package) {
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+
+	cfg := &types.Config{
+		Importer: sourceImporter(),
+		Error:    func(error) {},
+	}
+
+	pkg, _ := cfg.Check(file.Name.Name, fset, []*ast.File{file}, info)
+
+	return info, pkg
+}
+
+// sourceImporter returns an importer type checking imported packages from
+// source. The importer caches the imported packages so it's shared between all
+// calls.
+//
+//nolint:gochecknoglobals // Shared to cache imported packages.
+var sourceImporter = sync.OnceValue(func() types.Importer {
+	return &lockedImporter{
+		importer: importer.ForCompiler(token.NewFileSet(), "source", nil),
+	}
+})
+
+// lockedImporter makes an importer safe for concurrent use.
+type lockedImporter struct {
+	mu       sync.Mutex
+	importer types.Importer
+}
+
+func (l *lockedImporter) Import(path string) (*types.Package, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.importer.Import(path)
+}
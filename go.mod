@@ -3,6 +3,7 @@ module github.com/bombsimon/wsl/v5
 go 1.26.0
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/stretchr/testify v1.12.1
 	go.yaml.in/yaml/v3 v3.0.5
 	golang.org/x/tools v0.49.0
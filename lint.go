@@ -0,0 +1,146 @@
+package wsl
+
+import (
+	"cmp"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Issue is an issue found in a file.
+type Issue struct {
+	// Pos is the position the issue is reported at.
+	Pos token.Pos
+
+	// Message describes the issue, e.g. `missing whitespace above this line
+	// (no shared variables above if)`.
+	Message string
+
+	// CheckType is the check that found the issue.
+	CheckType CheckType
+
+	// Reason is the reason the issue was reported.
+	Reason Reason
+
+	// Severity is the configured severity for the check.
+	Severity Severity
+
+	// Edits are the edits fixing the issue, ordered by position. Some issues
+	// can't be fixed automatically in which case there are no edits. An edit
+	// fixing multiple issues is only added to the first one.
+	Edits []TextEdit
+}
+
+// TextEdit replaces the text between Pos and End with NewText. If Pos and End
+// are equal, NewText is inserted at Pos.
+type TextEdit struct {
+	Pos     token.Pos
+	End     token.Pos
+	NewText []byte
+}
+
+// Lint runs all checks enabled in the configuration on the file and returns
+// the issues ordered by position. The type information is used for checks
+// depending on types, e.g. the `err` check, and can be partial. If info is nil
+// or SyntaxOnly is set, heuristics are used instead. Generated files are
+// skipped unless IncludeGenerated is set and checks with SeverityOff are never
+// reported.
+func Lint(fset *token.FileSet, file *ast.File, info *types.Info, cfg *Configuration) []Issue {
+	return lint(fset, file, info, nil, nil, cfg)
+}
+
+// lint runs the checks on the file. The inspector is used to traverse the file
+// if it's set and contains the file.
+func lint(
+	fset *token.FileSet,
+	file *ast.File,
+	info *types.Info,
+	pkg *types.Package,
+	insp *inspector.Inspector,
+	cfg *Configuration,
+) []Issue {
+	if !cfg.IncludeGenerated && ast.IsGenerated(file) {
+		return nil
+	}
+
+	w := newWSL(fset, file, info, pkg, cfg)
+	w.inspector = insp
+	w.Run()
+
+	issues := make([]Issue, 0, len(w.issues))
+
+	for pos, issue := range w.issues {
+		severity := cfg.Severities.Get(issue.checkType)
+		if severity == SeverityOff {
+			continue
+		}
+
+		edits := make([]TextEdit, 0, len(issue.fixRanges))
+
+		for _, f := range issue.fixRanges {
+			edits = append(edits, TextEdit{
+				Pos:     f.fixRangeStart,
+				End:     f.fixRangeEnd,
+				NewText: f.fix,
+			})
+		}
+
+		issues = append(issues, Issue{
+			Pos:       pos,
+			Message:   issue.message,
+			CheckType: issue.checkType,
+			Reason:    issue.reason,
+			Severity:  severity,
+			Edits:     edits,
+		})
+	}
+
+	slices.SortFunc(issues, func(a, b Issue) int {
+		return cmp.Or(
+			cmp.Compare(a.Pos, b.Pos),
+			cmp.Compare(a.CheckType, b.CheckType),
+			strings.Compare(a.Message, b.Message),
+		)
+	})
+
+	dedupeEdits(issues)
+
+	return issues
+}
+
+// dedupeEdits sorts the edits for each issue and removes edits identical to
+// an edit already added to the same or a previous issue. Some checks, e.g.
+// grouping of declarations, reports multiple issues with the same fix.
+func dedupeEdits(issues []Issue) {
+	type editKey struct {
+		pos, end token.Pos
+		newText  string
+	}
+
+	seen := map[editKey]struct{}{}
+
+	for i := range issues {
+		edits := issues[i].Edits[:0]
+
+		slices.SortStableFunc(issues[i].Edits, func(a, b TextEdit) int {
+			return cmp.Or(cmp.Compare(a.Pos, b.Pos), cmp.Compare(a.End, b.End))
+		})
+
+		for _, e := range issues[i].Edits {
+			key := editKey{pos: e.Pos, end: e.End, newText: string(e.NewText)}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			edits = append(edits, e)
+		}
+
+		issues[i].Edits = edits
+	}
+}
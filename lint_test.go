@@ -0,0 +1,249 @@
+package wsl
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lintSource = `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	a := 1
+	if true {
+		fmt.Println("cuddled")
+	}
+	err := errors.New("x")
+
+	if err != nil {
+		panic(err)
+	}
+
+	_ = a
+}
+`
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", lintSource, parser.ParseComments)
+	require.NoError(t, err)
+
+	info, _ := typeCheckFile(fset, file)
+
+	cfg := NewConfig()
+	cfg.Checks.Add(CheckAfterBlock)
+	cfg.Severities[CheckAfterBlock] = SeverityWarning
+
+	type result struct {
+		line      int
+		checkType CheckType
+		reason    Reason
+		severity  Severity
+		edits     int
+	}
+
+	results := []result{}
+
+	for _, issue := range Lint(fset, file, info, cfg) {
+		results = append(results, result{
+			line:      fset.Position(issue.Pos).Line,
+			checkType: issue.CheckType,
+			reason:    issue.Reason,
+			severity:  issue.Severity,
+			edits:     len(issue.Edits),
+		})
+	}
+
+	assert.Equal(t, []result{
+		{10, CheckIf, ReasonNoIntersection, SeverityError, 1},
+		{12, CheckAfterBlock, ReasonMissingWhitespaceBelow, SeverityWarning, 1},
+		// The newline above line 13 is already added by the after-block fix.
+		{13, CheckAssign, ReasonInvalidTypeCuddle, SeverityError, 0},
+		{14, CheckErr, ReasonRemoveWhitespace, SeverityError, 1},
+	}, results)
+
+	// Without type information `err` is still found by its name.
+	assert.Equal(t, Lint(fset, file, info, cfg), Lint(fset, file, nil, cfg))
+
+	// Checks with severity off are never reported.
+	cfg.Severities[CheckIf] = SeverityOff
+
+	for _, issue := range Lint(fset, file, info, cfg) {
+		assert.NotEqual(t, CheckIf, issue.CheckType)
+	}
+}
+
+func TestLintDedupeEdits(t *testing.T) {
+	t.Parallel()
+
+	src := `package main
+
+func main() {
+	var a = 1
+	var b = 2
+	var c = 3
+
+	_, _, _ = a, b, c
+}
+`
+
+	lintOnce := func() []Issue {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+		require.NoError(t, err)
+
+		return Lint(fset, file, nil, NewConfig())
+	}
+
+	issues := lintOnce()
+	require.Len(t, issues, 2)
+
+	// Both declarations are grouped by the same fix which is only added to
+	// the first issue.
+	assert.Equal(t, CheckDecl, issues[0].CheckType)
+	assert.Len(t, issues[0].Edits, 1)
+	assert.Equal(t, CheckDecl, issues[1].CheckType)
+	assert.Empty(t, issues[1].Edits)
+
+	for range 10 {
+		assert.Equal(t, issues, lintOnce())
+	}
+}
+
+func TestDedupeEdits(t *testing.T) {
+	t.Parallel()
+
+	issues := []Issue{
+		{Edits: []TextEdit{
+			{Pos: 5, End: 5, NewText: []byte("\n")},
+			{Pos: 1, End: 3, NewText: []byte{}},
+			{Pos: 5, End: 5, NewText: []byte("\n")},
+		}},
+		{Edits: []TextEdit{
+			{Pos: 5, End: 5, NewText: []byte("x")},
+			{Pos: 1, End: 3, NewText: []byte{}},
+		}},
+	}
+
+	dedupeEdits(issues)
+
+	assert.Equal(t, []TextEdit{
+		{Pos: 1, End: 3, NewText: []byte{}},
+		{Pos: 5, End: 5, NewText: []byte("\n")},
+	}, issues[0].Edits)
+	assert.Equal(t, []TextEdit{
+		{Pos: 5, End: 5, NewText: []byte("x")},
+	}, issues[1].Edits)
+}
+
+func TestLintGenerated(t *testing.T) {
+	t.Parallel()
+
+	src := "// Code generated by test. DO NOT EDIT.\n\n" + lintSource
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	assert.Empty(t, Lint(fset, file, nil, cfg))
+
+	cfg.IncludeGenerated = true
+	assert.NotEmpty(t, Lint(fset, file, nil, cfg))
+}
+
+func TestLintSourceErrorFromOtherFile(t *testing.T) {
+	t.Parallel()
+
+	// `do` is declared in another file so the type of `err` is invalid when
+	// the source is type checked on its own.
+	src := `package a
+
+func f() {
+	err := do()
+
+	if err != nil {
+		panic(err)
+	}
+}
+`
+
+	cfg := NewConfig()
+	cfg.Checks = NoChecks()
+	cfg.Checks.Add(CheckErr)
+
+	issues, err := LintSource(token.NewFileSet(), "a.go", []byte(src), cfg)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, CheckErr, issues[0].CheckType)
+}
+
+func TestFormatSource(t *testing.T) {
+	t.Parallel()
+
+	cfg := NewConfig()
+	cfg.Checks.Add(CheckAfterBlock)
+
+	res, err := FormatSource([]byte(lintSource), cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	a := 1
+
+	if true {
+		fmt.Println("cuddled")
+	}
+
+	err := errors.New("x")
+	if err != nil {
+		panic(err)
+	}
+
+	_ = a
+}
+`, string(res))
+
+	// Formatting is idempotent.
+	again, err := FormatSource(res, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, string(res), string(again))
+
+	_, err = FormatSource([]byte("package"), cfg)
+	require.Error(t, err)
+}
+
+func TestApplyTextEdits(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("abcdef")
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("x.go", -1, len(src))
+	pos := func(offset int) token.Pos { return file.Pos(offset) }
+
+	res := applyTextEdits(file, src, []TextEdit{
+		{Pos: pos(4), End: pos(5), NewText: []byte("E")},
+		{Pos: pos(1), End: pos(1), NewText: []byte("\n")},
+		{Pos: pos(1), End: pos(1), NewText: []byte("\n")}, // Duplicate
+		{Pos: pos(3), End: pos(5), NewText: []byte("X")},  // Overlapping
+	})
+
+	assert.Equal(t, "a\nbcXf", string(res))
+}
@@ -0,0 +1,33 @@
+package wsl
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// checkCompositeLit checks for leading and trailing empty lines inside the
+// braces of a composite literal.
+func (w *WSL) checkCompositeLit(lit *ast.CompositeLit) {
+	if len(lit.Elts) == 0 {
+		return
+	}
+
+	w.checkLeadingNewlineBefore(lit.Lbrace, lit.Elts[0].Pos(), CheckCompositeWhitespace)
+	w.checkTrailingNewlineAfter(lit.Elts[len(lit.Elts)-1].End(), lit.Rbrace, CheckCompositeWhitespace)
+}
+
+// checkCallArgs checks for leading and trailing empty lines inside the
+// parentheses of a function call.
+func (w *WSL) checkCallArgs(call *ast.CallExpr) {
+	if len(call.Args) == 0 {
+		return
+	}
+
+	lastArgEnd := call.Args[len(call.Args)-1].End()
+	if call.Ellipsis.IsValid() {
+		lastArgEnd = call.Ellipsis + token.Pos(len(token.ELLIPSIS.String()))
+	}
+
+	w.checkLeadingNewlineBefore(call.Lparen, call.Args[0].Pos(), CheckCallWhitespace)
+	w.checkTrailingNewlineAfter(lastArgEnd, call.Rparen, CheckCallWhitespace)
+}
@@ -0,0 +1,226 @@
+package wsl
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// DefaultLockMethods are the methods that can be cuddled above any statement
+// if LockMethods isn't set.
+//
+//nolint:gochecknoglobals // Used as a constant.
+var DefaultLockMethods = []string{"Lock", "RLock", "RWLock", "TryLock"}
+
+// DefaultUnlockMethods are the methods that can be cuddled below any statement
+// if UnlockMethods isn't set.
+//
+//nolint:gochecknoglobals // Used as a constant.
+var DefaultUnlockMethods = []string{"Unlock", "RUnlock", "RWUnlock"}
+
+// ParseLockType splits a lock type in the form `import/path.Name` to the
+// package path and the type name.
+func ParseLockType(s string) (string, string, error) {
+	i := strings.LastIndex(s, ".")
+	if i <= 0 || i == len(s)-1 {
+		return "", "", fmt.Errorf("invalid lock type '%s', must be `import/path.Name`", s)
+	}
+
+	return s[:i], s[i+1:], nil
+}
+
+func (w *WSL) isLockOrUnlock(current, previous ast.Node) bool {
+	// If we're an ExprStmt (e.g. X()), we check if we're calling `Unlock` or
+	// `RUnlock`. No matter how deep this is or what previous statement was, we
+	// allow this.
+	//
+	// mu.Lock()
+	// [ANY BLOCK]
+	// mu.Unlock()
+	if _, ok := current.(*ast.ExprStmt); ok {
+		return w.hasLockCall(current, w.unlockMethods())
+	}
+
+	if previous != nil {
+		return w.hasLockCall(previous, w.lockMethods())
+	}
+
+	return false
+}
+
+func (w *WSL) lockMethods() []string {
+	if w.config.LockMethods == nil {
+		return DefaultLockMethods
+	}
+
+	return w.config.LockMethods
+}
+
+func (w *WSL) unlockMethods() []string {
+	if w.config.UnlockMethods == nil {
+		return DefaultUnlockMethods
+	}
+
+	return w.config.UnlockMethods
+}
+
+// hasLockCall checks if node contains a selector call with one of the given
+// names on a receiver matching the configured lock types. Selectors within
+// blocks are ignored.
+func (w *WSL) hasLockCall(node ast.Node, methods []string) bool {
+	var found bool
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found {
+			return false // Already found
+		}
+
+		if _, ok := n.(*ast.BlockStmt); ok {
+			return false
+		}
+
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			found = slices.Contains(methods, sel.Sel.Name) && w.isLockType(sel.X)
+			return false
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// isLockType checks if the receiver has one of the configured lock types or
+// implements one of them if it's an interface. If no lock types are
+// configured or there's no type information, any receiver is allowed.
+func (w *WSL) isLockType(receiver ast.Expr) bool {
+	// Without type information we can't restrict the receiver so any receiver
+	// is allowed.
+	if len(w.config.LockTypes) == 0 || w.typeInfo == nil {
+		return true
+	}
+
+	typ := w.typeInfo.TypeOf(receiver)
+	if typ == nil {
+		return false
+	}
+
+	for _, lockType := range w.config.LockTypes {
+		path, name, err := ParseLockType(lockType)
+		if err != nil {
+			continue
+		}
+
+		if isNamedType(typ, path, name) {
+			return true
+		}
+
+		iface := w.lookupInterface(path, name)
+		if iface == nil {
+			continue
+		}
+
+		if types.Implements(typ, iface) || types.Implements(types.NewPointer(typ), iface) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNamedType checks if typ, or the type it points to, is the named type name
+// in the package path.
+func isNamedType(typ types.Type, path, name string) bool {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+
+	return obj.Pkg() != nil && obj.Pkg().Path() == path && obj.Name() == name
+}
+
+// lookupInterface finds the interface name in the package path among the
+// packages imported by the current package. Since types can implement
+// `sync.Locker` without importing `sync` it's always available.
+func (w *WSL) lookupInterface(path, name string) *types.Interface {
+	if path == "sync" && name == "Locker" {
+		return syncLocker()
+	}
+
+	pkg := w.findPackage(path)
+	if pkg == nil {
+		return nil
+	}
+
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+
+	iface, _ := obj.Type().Underlying().(*types.Interface)
+
+	return iface
+}
+
+// findPackage finds the package with the import path among the packages
+// imported by the current package. Without the current package, e.g. when
+// linting a single file, the packages used in the file are searched.
+func (w *WSL) findPackage(path string) *types.Package {
+	seen := map[*types.Package]struct{}{}
+
+	if w.pkg != nil {
+		return findImport(w.pkg, path, seen)
+	}
+
+	for _, obj := range w.typeInfo.Uses {
+		pkgName, ok := obj.(*types.PkgName)
+		if !ok {
+			continue
+		}
+
+		if pkg := findImport(pkgName.Imported(), path, seen); pkg != nil {
+			return pkg
+		}
+	}
+
+	return nil
+}
+
+func findImport(pkg *types.Package, path string, seen map[*types.Package]struct{}) *types.Package {
+	if pkg.Path() == path {
+		return pkg
+	}
+
+	if _, ok := seen[pkg]; ok {
+		return nil
+	}
+
+	seen[pkg] = struct{}{}
+
+	for _, imp := range pkg.Imports() {
+		if found := findImport(imp, path, seen); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// syncLocker creates an interface equal to `sync.Locker`.
+func syncLocker() *types.Interface {
+	noArgs := types.NewSignatureType(nil, nil, nil, nil, nil, false)
+
+	return types.NewInterfaceType([]*types.Func{
+		types.NewFunc(token.NoPos, nil, "Lock", noArgs),
+		types.NewFunc(token.NoPos, nil, "Unlock", noArgs),
+	}, nil).Complete()
+}
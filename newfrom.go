@@ -0,0 +1,278 @@
+package wsl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lineRange is an inclusive range of lines.
+type lineRange struct {
+	start int
+	end   int
+}
+
+// changedLines holds the added or changed lines in the new version of each
+// file, keyed by the absolute path of the file.
+type changedLines map[string][]lineRange
+
+// add marks the lines from start to end (inclusive) in filename as changed.
+func (c changedLines) add(filename string, start, end int) {
+	c[filename] = append(c[filename], lineRange{start: start, end: end})
+}
+
+// intersects reports if any line from start to end (inclusive) in filename is
+// changed.
+func (c changedLines) intersects(filename string, start, end int) bool {
+	for _, r := range c[filepath.Clean(filename)] {
+		if start <= r.end && end >= r.start {
+			return true
+		}
+	}
+
+	return false
+}
+
+// includes reports if the issue, or any of the edits fixing it, is on a
+// changed line.
+func (c changedLines) includes(fset *token.FileSet, issue Issue) bool {
+	pos := fset.PositionFor(issue.Pos, false)
+	if c.intersects(pos.Filename, pos.Line, pos.Line) {
+		return true
+	}
+
+	for _, e := range issue.Edits {
+		start := fset.PositionFor(e.Pos, false)
+
+		end := start
+		if e.End.IsValid() {
+			end = fset.PositionFor(e.End, false)
+		}
+
+		if c.intersects(start.Filename, start.Line, end.Line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// changedLinesFromRev returns the lines changed since rev in the git repository
+// in the working directory, including all untracked files.
+func changedLinesFromRev(rev string) (changedLines, error) {
+	root, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	root = strings.TrimSpace(root)
+
+	diff, err := gitOutput("diff", "--no-color", "--no-ext-diff", "-U0", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := parseUnifiedDiff(strings.NewReader(diff), root)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := gitOutput("ls-files", "--others", "--exclude-standard", "--full-name", ":/")
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range strings.Lines(untracked) {
+		if name = strings.TrimSpace(name); name != "" {
+			changes.add(filepath.Join(root, filepath.FromSlash(name)), 1, math.MaxInt)
+		}
+	}
+
+	return changes, nil
+}
+
+// changedLinesFromPatch returns the lines changed in the unified diff in the
+// file, or stdin if the filename is "-". Paths in the patch are relative to the
+// root of the git repository in the working directory, or the working
+// directory if it's not within a git repository.
+func changedLinesFromPatch(filename string) (changedLines, error) {
+	var (
+		patch []byte
+		err   error
+	)
+
+	if filename == "-" {
+		patch, err = io.ReadAll(os.Stdin)
+	} else {
+		patch, err = os.ReadFile(filename)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch: %w", err)
+	}
+
+	root, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		root, err = os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return parseUnifiedDiff(bytes.NewReader(patch), strings.TrimSpace(root))
+}
+
+func gitOutput(args ...string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return string(out), nil
+}
+
+// parseUnifiedDiff returns the lines added in the new version of each file in
+// the diff. Since removing lines is a big part of what wsl does, the lines
+// surrounding removed lines are also considered changed. Relative paths are
+// joined with root.
+func parseUnifiedDiff(r io.Reader, root string) (changedLines, error) {
+	changes := changedLines{}
+
+	var (
+		filename     string
+		line         int
+		oldRemaining int
+		newRemaining int
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		if oldRemaining > 0 || newRemaining > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				changes.add(filename, line, line)
+
+				line++
+				newRemaining--
+			case strings.HasPrefix(text, "-"):
+				changes.add(filename, line-1, line)
+
+				oldRemaining--
+			case strings.HasPrefix(text, `\`):
+				// No newline at end of file.
+			default:
+				line++
+				oldRemaining--
+				newRemaining--
+			}
+
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			filename = diffFilename(strings.TrimPrefix(text, "+++ "), root)
+		case strings.HasPrefix(text, "@@ "):
+			var err error
+
+			line, oldRemaining, newRemaining, err = parseHunkHeader(text)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+
+	delete(changes, "")
+
+	return changes, nil
+}
+
+// diffFilename returns the absolute path of the file in a `+++` line, or an
+// empty string if the file is deleted.
+func diffFilename(name, root string) string {
+	// A tab separates the name from the timestamp in diffs not produced by
+	// git.
+	name, _, _ = strings.Cut(name, "\t")
+
+	if name == "/dev/null" {
+		return ""
+	}
+
+	name = strings.TrimPrefix(name, "b/")
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(root, filepath.FromSlash(name))
+	}
+
+	return filepath.Clean(name)
+}
+
+// parseHunkHeader returns the first line in the new file and the number of
+// lines in the old and new file for a hunk header, e.g. `@@ -1,2 +1,3 @@`. For
+// hunks only removing lines, the first line is the one after the removed
+// lines.
+func parseHunkHeader(header string) (int, int, int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header '%s'", header)
+	}
+
+	_, oldLines, err := parseHunkRange(fields[1][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header '%s'", header)
+	}
+
+	line, newLines, err := parseHunkRange(fields[2][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header '%s'", header)
+	}
+
+	// An empty range starts at the line before the change.
+	if newLines == 0 {
+		line++
+	}
+
+	return line, oldLines, newLines, nil
+}
+
+// parseHunkRange parses a range in a hunk header, e.g. `1,2`. The number of
+// lines is 1 if omitted.
+func parseHunkRange(s string) (int, int, error) {
+	start, count, hasCount := strings.Cut(s, ",")
+
+	line, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !hasCount {
+		return line, 1, nil
+	}
+
+	lines, err := strconv.Atoi(count)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return line, lines, nil
+}
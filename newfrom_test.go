@@ -0,0 +1,115 @@
+package wsl
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.FromSlash("/repo")
+
+	for _, tc := range []struct {
+		name     string
+		diff     string
+		expected changedLines
+	}{
+		{
+			name: "git diff with context",
+			diff: `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,5 +1,6 @@
+ package main
+ 
++import "fmt"
+ func main() {
+-	x := 1
++	y := 1
+ }
+`,
+			expected: changedLines{
+				filepath.Join(root, "main.go"): {{3, 3}, {4, 5}, {5, 5}},
+			},
+		},
+		{
+			name: "only removed lines without context",
+			diff: `--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -3 +2,0 @@ func f() {
+-
+@@ -10,2 +8,0 @@ func g() {
+-	a := 1
+--- comment starting with dashes
+`,
+			expected: changedLines{
+				filepath.Join(root, "pkg", "a.go"): {{2, 3}, {8, 9}, {8, 9}},
+			},
+		},
+		{
+			name: "new and deleted files",
+			diff: `--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package main
++
+\ No newline at end of file
+--- a/deleted.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package main
+`,
+			expected: changedLines{
+				filepath.Join(root, "new.go"): {{1, 1}, {2, 2}},
+			},
+		},
+		{
+			name: "diff with timestamps and absolute paths",
+			diff: "--- /tmp/a.go\t2024-01-01 00:00:00\n+++ /tmp/b.go\t2024-01-01 00:00:00\n@@ -1 +1 @@\n-a\n+b\n",
+			expected: changedLines{
+				filepath.FromSlash("/tmp/b.go"): {{0, 1}, {1, 1}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			changes, err := parseUnifiedDiff(strings.NewReader(tc.diff), root)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, changes)
+		})
+	}
+}
+
+func TestParseUnifiedDiffInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, diff := range []string{
+		"+++ b/a.go\n@@ -1 @@\n",
+		"+++ b/a.go\n@@ -1 +x,1 @@\n",
+		"+++ b/a.go\n@@ -1,x +1 @@\n",
+	} {
+		_, err := parseUnifiedDiff(strings.NewReader(diff), "/")
+		require.ErrorContains(t, err, "invalid hunk header", diff)
+	}
+}
+
+func TestChangedLinesIntersects(t *testing.T) {
+	t.Parallel()
+
+	changes := changedLines{}
+	changes.add("/repo/a.go", 3, 5)
+
+	assert.False(t, changes.intersects("/repo/a.go", 1, 2))
+	assert.True(t, changes.intersects("/repo/a.go", 1, 3))
+	assert.True(t, changes.intersects("/repo/a.go", 4, 4))
+	assert.True(t, changes.intersects("/repo/./a.go", 5, 9))
+	assert.False(t, changes.intersects("/repo/a.go", 6, 9))
+	assert.False(t, changes.intersects("/repo/b.go", 4, 4))
+}
@@ -0,0 +1,84 @@
+package wsl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reason is the reason an issue was reported. Each reason has a stable code
+// returned by String that is set as the `reason` query parameter of
+// Diagnostic.URL so tools can act on it without parsing the message.
+type Reason int
+
+const (
+	ReasonInvalid Reason = iota
+	// ReasonInvalidTypeCuddle is reported when a statement is cuddled with a
+	// statement of a type it's not allowed to be cuddled with.
+	ReasonInvalidTypeCuddle
+	// ReasonTooManyStatements is reported when more statements than allowed
+	// are cuddled above a statement.
+	ReasonTooManyStatements
+	// ReasonNoIntersection is reported when a statement is cuddled with a
+	// statement that doesn't share any variables with it.
+	ReasonNoIntersection
+	// ReasonVariableNotShared is reported when a statement in a cuddled group
+	// doesn't share any variables with the statement below the group.
+	ReasonVariableNotShared
+	// ReasonTooManyLines is reported when a branch statement is cuddled in a
+	// block with more lines than allowed.
+	ReasonTooManyLines
+	// ReasonNeverCuddle is reported when a statement that should never be
+	// cuddled is cuddled.
+	ReasonNeverCuddle
+	// ReasonRemoveWhitespace is reported when an empty line should be removed.
+	ReasonRemoveWhitespace
+	// ReasonMissingWhitespaceAbove is reported when an empty line is missing
+	// above a statement for any other reason than the ones above.
+	ReasonMissingWhitespaceAbove
+	// ReasonMissingWhitespaceBelow is reported when an empty line is missing
+	// below a statement.
+	ReasonMissingWhitespaceBelow
+	// ReasonUnusedDirective is reported for directives not suppressing any
+	// issue.
+	ReasonUnusedDirective
+)
+
+func (r Reason) String() string {
+	return [...]string{
+		"invalid",
+		"invalid-type-cuddle",
+		"too-many-statements",
+		"no-intersection",
+		"variable-not-shared",
+		"too-many-lines",
+		"never-cuddle",
+		"remove-whitespace",
+		"missing-whitespace-above",
+		"missing-whitespace-below",
+		"unused-directive",
+	}[r]
+}
+
+func ReasonFromString(s string) (Reason, error) {
+	for r := ReasonInvalidTypeCuddle; r <= ReasonUnusedDirective; r++ {
+		if strings.EqualFold(r.String(), s) {
+			return r, nil
+		}
+	}
+
+	return ReasonInvalid, fmt.Errorf("invalid reason '%s'", s)
+}
+
+// message returns the base message for the reason.
+func (r Reason) message() string {
+	switch r {
+	case ReasonRemoveWhitespace:
+		return messageRemoveWhitespace
+	case ReasonMissingWhitespaceBelow:
+		return messageMissingWhitespaceBelow
+	case ReasonUnusedDirective:
+		return messageUnusedDirective
+	default:
+		return messageMissingWhitespaceAbove
+	}
+}
@@ -0,0 +1,24 @@
+package wsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReasonToAndFromString(t *testing.T) {
+	t.Parallel()
+
+	for r := ReasonInvalid; r <= ReasonUnusedDirective; r++ {
+		reason, err := ReasonFromString(r.String())
+
+		if r == ReasonInvalid {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, r, reason)
+	}
+}
@@ -0,0 +1,111 @@
+package wsl
+
+import (
+	"go/ast"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The functions in this file are used instead of type information when it's
+// not available, either because SyntaxOnly is set or because no type
+// information was passed when linting. They're heuristics based on naming
+// conventions so they can be wrong both ways.
+
+// looksLikeErr returns true if the expression is named like an error, e.g.
+// `err`, `readErr` or `res.Err`.
+func looksLikeErr(expr ast.Expr) bool {
+	var name string
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	default:
+		return false
+	}
+
+	return name == "err" ||
+		strings.HasSuffix(name, "Err") ||
+		hasWordPrefix(name, "err") ||
+		hasWordPrefix(name, "Err")
+}
+
+// hasWordPrefix returns true if name starts with prefix followed by an upper
+// case letter or a digit, e.g. `errRead` or `err2` for `err` but not `errand`.
+func hasWordPrefix(name, prefix string) bool {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok || rest == "" {
+		return false
+	}
+
+	r := []rune(rest)[0]
+
+	return unicode.IsUpper(r) || unicode.IsDigit(r)
+}
+
+// isTypeLikeIdent returns true if the identifier is most likely not a
+// variable, i.e. a predeclared identifier like `string` or `nil`, a package
+// name or a capitalised identifier that's not a field or method. The parent is
+// the selector the identifier is part of, if any.
+func (w *WSL) isTypeLikeIdent(ident *ast.Ident, parent *ast.SelectorExpr) bool {
+	if parent != nil && parent.Sel == ident {
+		return false
+	}
+
+	if parent != nil && parent.X == ident && w.isImportName(ident.Name) {
+		return true
+	}
+
+	switch types.Universe.Lookup(ident.Name).(type) {
+	case *types.TypeName, *types.Const, *types.Nil:
+		return true
+	}
+
+	return ident.IsExported()
+}
+
+// isImportName returns true if name is the name of an imported package in the
+// file. Without type information the package name is assumed to be the last
+// element of the import path ignoring any major version suffix.
+func (w *WSL) isImportName(name string) bool {
+	for _, spec := range w.file.Imports {
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return true
+			}
+
+			continue
+		}
+
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		base := path.Base(importPath)
+		if isMajorVersion(base) {
+			base = path.Base(path.Dir(importPath))
+		}
+
+		if base == name || strings.TrimPrefix(base, "go-") == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isMajorVersion(s string) bool {
+	rest, ok := strings.CutPrefix(s, "v")
+	if !ok || rest == "" {
+		return false
+	}
+
+	_, err := strconv.Atoi(rest)
+
+	return err == nil
+}
@@ -0,0 +1,141 @@
+package wsl
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLooksLikeErr(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		expr     string
+		expected bool
+	}{
+		{"err", true},
+		{"err2", true},
+		{"errRead", true},
+		{"readErr", true},
+		{"ErrNotFound", true},
+		{"res.Err", true},
+		{"res.readErr", true},
+		{"errand", false},
+		{"error", false},
+		{"e", false},
+		{"res.Value", false},
+		{"fn()", false},
+	} {
+		expr, err := parser.ParseExpr(tc.expr)
+		require.NoError(t, err)
+
+		assert.Equal(t, tc.expected, looksLikeErr(expr), tc.expr)
+	}
+}
+
+func TestIdentsFromNodeSyntaxOnly(t *testing.T) {
+	t.Parallel()
+
+	src := `package main
+
+import (
+	"fmt"
+	yaml "go.yaml.in/yaml/v3"
+	"github.com/bombsimon/wsl/v5"
+)
+
+func fn() {
+	_ = fmt.Sprint(x, s.Field, s.field, []string{}, nil, true, Max, wsl.New, yaml.Marshal, T{})
+}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	require.NoError(t, err)
+
+	w := newWSL(fset, file, nil, nil, NewConfig())
+	body := file.Decls[1].(*ast.FuncDecl).Body
+
+	names := []string{}
+	for _, ident := range w.identsFromNode(body, false) {
+		names = append(names, ident.Name)
+	}
+
+	// Package names, predeclared identifiers and capitalised identifiers that
+	// aren't fields are skipped.
+	assert.Equal(t, []string{"Sprint", "x", "s", "Field", "field", "New", "Marshal"}, names)
+}
+
+// TestSyntaxOnlyDegradation runs all tests for the default configuration with
+// and without type information to document what checks degrade without type
+// information.
+func TestSyntaxOnlyDegradation(t *testing.T) {
+	t.Parallel()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "src", "default_config", "*", "*.go"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	type key struct {
+		position  string
+		checkType CheckType
+		reason    Reason
+	}
+
+	var onlyTyped, onlySyntax []key
+
+	for _, filename := range files {
+		src, err := os.ReadFile(filename)
+		require.NoError(t, err)
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+		require.NoError(t, err)
+
+		info, pkg := typeCheckFile(fset, file)
+
+		cfg := NewConfig()
+		syntaxCfg := NewConfig()
+		syntaxCfg.SyntaxOnly = true
+
+		toKeys := func(issues []Issue) map[key]struct{} {
+			keys := map[key]struct{}{}
+			for _, issue := range issues {
+				keys[key{fset.Position(issue.Pos).String(), issue.CheckType, issue.Reason}] = struct{}{}
+			}
+
+			return keys
+		}
+
+		typed := toKeys(lint(fset, file, info, pkg, nil, cfg))
+		syntax := toKeys(lint(fset, file, info, pkg, nil, syntaxCfg))
+
+		for k := range typed {
+			if _, ok := syntax[k]; !ok {
+				onlyTyped = append(onlyTyped, k)
+			}
+		}
+
+		for k := range syntax {
+			if _, ok := typed[k]; !ok {
+				onlySyntax = append(onlySyntax, k)
+			}
+		}
+	}
+
+	// Only the `err` check depends on types with the default configuration.
+	// Errors not named like errors are missed and variables named like errors
+	// that aren't are reported.
+	for _, k := range append(onlyTyped, onlySyntax...) {
+		assert.Equal(t, CheckErr, k.checkType, k.position)
+	}
+
+	assert.Len(t, onlyTyped, 1, "%v", onlyTyped)
+	assert.Len(t, onlySyntax, 2, "%v", onlySyntax)
+}
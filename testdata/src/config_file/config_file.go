@@ -0,0 +1,11 @@
+package testpkg
+
+import "fmt"
+
+func fn() {
+	x := 5
+	if x > 0 {
+		fmt.Println("positive")
+	} // want `^warning: missing whitespace below this line \(after-block\)`
+	fmt.Println("not checked since `expr` is disabled in the config file")
+}
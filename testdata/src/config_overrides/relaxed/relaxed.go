@@ -0,0 +1,11 @@
+package relaxed
+
+import "fmt"
+
+func fn() {
+	x := 1
+	if true {
+		fmt.Println("block")
+	} // want `missing whitespace below this line \(after-block\)`
+	_ = x // if is disabled here
+}
@@ -0,0 +1,11 @@
+package strict
+
+import "fmt"
+
+func fn() {
+	x := 1
+	if true { // want `missing whitespace above this line \(no shared variables above if\)`
+		fmt.Println("block")
+	}
+	_ = x // after-block is not enabled here
+}
@@ -73,3 +73,24 @@ func withDefer(mu sync.Mutex, items []int) {
 		_ = i
 	}
 }
+
+func readLock(mu *sync.RWMutex, items []int) {
+	mu.RLock()
+	for _, i := range items {
+		_ = i
+	}
+	mu.RUnlock()
+}
+
+type T struct {
+	mu sync.Mutex
+	ok bool
+}
+
+func tryLock(t *T, items []int) {
+	t.ok = t.mu.TryLock()
+	for _, i := range items {
+		_ = i
+	}
+	t.mu.Unlock()
+}
@@ -0,0 +1,55 @@
+package testpkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+func unchanged() {
+	a := 1
+	if true {
+		fmt.Println("x")
+	}
+
+	_ = a
+}
+
+func addedLine() {
+	a := 1
+	if true { // want `missing whitespace above this line \(no shared variables above if\)`
+		fmt.Println("x")
+	}
+
+	_ = a
+}
+
+func removedLine() {
+	a := 1
+	if true { // want `missing whitespace above this line \(no shared variables above if\)`
+		fmt.Println("x")
+	}
+
+	_ = a
+}
+
+func changedFix() {
+	a := 1
+	err := errors.New("changed") // want +1 `unnecessary whitespace \(err\)`
+
+	if err != nil {
+		panic(err)
+	}
+
+	_ = a
+}
+
+func unchangedFix() {
+	a := 1
+	err := errors.New("x")
+
+	if err != nil {
+		panic(err)
+	}
+
+	_ = a
+}
@@ -358,3 +358,19 @@ func funcLiteralAssigned() {
 	_ = someFn
 	_ = anotherVar
 }
+
+func blockLastInCaseWithComment(x int) {
+	switch x {
+	case 1:
+		if x > 0 {
+			fmt.Println(x)
+		}
+	} // Comment on the closing brace.
+
+	select {
+	default:
+		if x > 0 {
+			fmt.Println(x)
+		}
+	} // Comment on the closing brace.
+}
@@ -159,3 +159,15 @@ func unnecessaryBlankBeforeCase(n int) {
 		n++
 	}
 }
+
+//wsl:ignore case-trailing-newline
+func ignored(n int) {
+	switch n {
+	case 1:
+		n++
+		n++
+		n++
+	case 2:
+		n++
+	}
+}
@@ -0,0 +1,50 @@
+package testpkg
+
+import "fmt"
+
+type T struct {
+	a int
+	b int
+}
+
+func composite() {
+	_ = []int{ // want +1 `unnecessary whitespace \(composite-whitespace\)`
+
+		1,
+		2, // want +1 `unnecessary whitespace \(composite-whitespace\)`
+
+	}
+
+	_ = map[string]int{
+		// Leading comment.
+		"a": 1,
+		"b": 2,
+		// Trailing comment.
+	}
+
+	_ = T{ // want +1 `unnecessary whitespace \(composite-whitespace\)`
+
+		a: 1,
+		b: 2,
+	}
+
+	_ = []T{}
+	_ = []int{1, 2}
+}
+
+func call(args ...any) {
+	fmt.Println( // want +1 `unnecessary whitespace \(call-whitespace\)`
+
+		"a",
+		"b",
+	)
+
+	fmt.Println(
+		"a",
+		args..., // want +1 `unnecessary whitespace \(call-whitespace\)`
+
+	)
+
+	fmt.Println()
+	fmt.Println("a", "b")
+}
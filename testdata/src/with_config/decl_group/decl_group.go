@@ -0,0 +1,38 @@
+package testpkg
+
+var ( // want +1 `unnecessary whitespace \(decl-group\)`
+
+	a = 1
+	b = 2 // want +1 `unnecessary whitespace \(decl-group\)`
+
+)
+
+const (
+	// c is documented.
+	// want +1 `unnecessary whitespace \(decl-group\)`
+
+	c = 3
+
+	// Section comment.
+
+	d = 4
+)
+
+type (
+	// T is a type.
+	T int
+
+	// U is another type.
+	U string // want +1 `unnecessary whitespace \(decl-group\)`
+
+)
+
+func fn() {
+	var ( // want +1 `unnecessary whitespace \(decl-group\)`
+
+		x = 1
+		y = 2
+	)
+
+	_, _ = x, y
+}
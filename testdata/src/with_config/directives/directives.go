@@ -0,0 +1,85 @@
+package testpkg
+
+import "fmt"
+
+func ignoreNextStatement() {
+	//wsl:ignore
+	if true {
+		a := 1
+		if true {
+			fmt.Println("a")
+		}
+	}
+
+	if true {
+		b := 1
+		if true { // want `missing whitespace above this line \(no shared variables above if\)`
+			fmt.Println("b")
+		}
+	}
+}
+
+func ignoreSpecificChecks() {
+	a := 1
+	if true { //wsl:ignore if,for
+		fmt.Println("a")
+	}
+
+	b := 1
+	if true { //wsl:ignore for // want `missing whitespace above this line \(no shared variables above if\)` `unused directive \(unused-directive\)`
+		fmt.Println("b")
+	}
+}
+
+func ignoreTrailing() {
+	a := 1
+	if true { //wsl:ignore
+		fmt.Println("a")
+	}
+}
+
+func ignoreCoversWholeStatement() {
+	//wsl:ignore
+	for i := range 10 {
+
+		fmt.Println(i)
+	}
+}
+
+// ignoreWholeFunction is ignored by the directive in the doc.
+//
+//wsl:ignore
+func ignoreWholeFunction() {
+
+	a := 1
+	if true {
+		fmt.Println("a")
+	}
+
+}
+
+func unusedDirectives() {
+	a := 1
+	//wsl:ignore // want `unused directive \(unused-directive\)`
+	fmt.Println(a)
+
+	//wsl:ignore for // want `unused directive \(unused-directive\)`
+	fmt.Println(a)
+
+	fmt.Println(a) //wsl:ignore // want `unused directive \(unused-directive\)`
+
+	//wsl:ignore // want `unused directive \(unused-directive\)`
+
+	//wsl:ignore not-a-check // want `unused directive \(unused-directive\)`
+	for i := range 10 { // want +1 `unnecessary whitespace \(leading-whitespace\)`
+
+		fmt.Println(i)
+	}
+}
+
+func notADirective() {
+	a := 1
+	if true { // wsl:ignore // want `missing whitespace above this line \(no shared variables above if\)`
+		fmt.Println("a")
+	}
+}
@@ -0,0 +1,15 @@
+//wsl:file-ignore leading-whitespace
+
+package testpkg
+
+import "fmt"
+
+func fileIgnoreLeading() {
+
+	fmt.Println("ignored")
+}
+
+func fileIgnoreOnlyLeading() {
+	fmt.Println("not ignored") // want +1 `unnecessary whitespace \(trailing-whitespace\)`
+
+}
@@ -0,0 +1,88 @@
+package testpkg
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+type result struct {
+	Err   error
+	Value int
+}
+
+func do() result { return result{} }
+
+func fn() error { return nil }
+
+func selector() {
+	res := do()
+
+	if res.Err != nil {
+		panic(res.Err)
+	}
+}
+
+func selectorNotError() {
+	res := do()
+
+	if res.Value != 0 {
+		panic(res.Value)
+	}
+}
+
+func errorsIs() {
+	err := fn()
+
+	if errors.Is(err, io.EOF) {
+		return
+	}
+}
+
+func errorsAs() {
+	var target *os.PathError
+
+	err := fn()
+
+	if !errors.As(err, &target) {
+		return
+	}
+}
+
+func commaOkMap(m map[string]int) {
+	v, ok := m["key"]
+
+	if !ok {
+		return
+	}
+
+	_ = v
+}
+
+func commaOkTypeAssert(x any) {
+	s, ok := x.(string)
+
+	if ok {
+		_ = s
+	}
+}
+
+func commaOkChannel(ch chan int) {
+	a := 1
+	v, ok := <-ch // want `missing whitespace above this line \(too many statements above if\)`
+	if !ok {
+		return
+	}
+
+	_, _ = a, v
+}
+
+func notCommaOk(f func() (int, bool)) {
+	v, ok := f()
+
+	if !ok {
+		return
+	}
+
+	_ = v
+}
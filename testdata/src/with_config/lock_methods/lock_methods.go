@@ -0,0 +1,24 @@
+package testpkg
+
+import "sync"
+
+type Semaphore struct{}
+
+func (*Semaphore) Acquire() {}
+func (*Semaphore) Release() {}
+
+func customMethods(sem *Semaphore, items []int) {
+	sem.Acquire()
+	for _, i := range items {
+		_ = i
+	}
+	sem.Release()
+}
+
+func defaultMethodsReplaced(mu *sync.RWMutex, items []int) {
+	mu.RLock()
+	for _, i := range items { // want `missing whitespace above this line \(invalid statement above range\)`
+		_ = i
+	}
+	mu.RUnlock() // want `missing whitespace above this line \(invalid statement above expr\)`
+}
@@ -0,0 +1,58 @@
+package testpkg
+
+import (
+	"log"
+	"sync"
+)
+
+type Semaphore struct{}
+
+func (*Semaphore) Lock() {}
+
+type Locker struct{}
+
+func (Locker) Lock()   {}
+func (Locker) Unlock() {}
+
+type Door struct{}
+
+func (Door) Lock() {}
+
+func mutex(mu *sync.Mutex, items []int) {
+	mu.Lock()
+	for _, i := range items {
+		_ = i
+	}
+	mu.Unlock()
+}
+
+func listedType(sem Semaphore, items []int) {
+	sem.Lock()
+	for _, i := range items {
+		_ = i
+	}
+}
+
+func implementsLocker(l Locker, items []int) {
+	l.Lock()
+	for _, i := range items {
+		_ = i
+	}
+	l.Unlock()
+}
+
+func lockerInterface(l sync.Locker, items []int) {
+	l.Lock()
+	for _, i := range items {
+		_ = i
+	}
+	l.Unlock()
+}
+
+func notALocker(d Door, l *log.Logger, items []int) {
+	d.Lock()
+	for _, i := range items { // want `missing whitespace above this line \(invalid statement above range\)`
+		_ = i
+	}
+	l.Print("done") // want `missing whitespace above this line \(invalid statement above expr\)`
+}
@@ -0,0 +1,31 @@
+package testpkg
+
+import "fmt"
+
+func afterBlockWarning() {
+	x := 5
+	if x > 0 {
+		fmt.Println("positive")
+	} // want `^warning: missing whitespace below this line \(after-block\)`
+	_ = x
+}
+
+func leadingWhitespaceError() { // want +1 `^unnecessary whitespace \(leading-whitespace\)`
+
+	fmt.Println("hello")
+}
+
+func returnInfo() int {
+	x := 1
+	x++
+	fmt.Println(x)
+	return x // want `^info: missing whitespace above this line \(too many lines above return\)`
+}
+
+func assignOff() {
+	a := 1
+	fmt.Println(a)
+	b := 2
+
+	_ = b
+}
@@ -0,0 +1,54 @@
+package testpkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+const Max = 10
+
+func fn() error { return nil }
+
+func errByName() {
+	err := fn() // want +1 `unnecessary whitespace \(err\)`
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+func errNotNamedLikeError() {
+	// Without type information we don't know that this is an error.
+	failure := fn()
+
+	if failure != nil {
+		panic(failure)
+	}
+}
+
+func namedLikeErrorButNot() {
+	// Without type information this is assumed to be an error.
+	err := 1 // want +1 `unnecessary whitespace \(err\)`
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+func errorsIs() {
+	err := fn()
+	if errors.Is(err, errors.ErrUnsupported) {
+		return
+	}
+}
+
+func capitalisedIdentsAreTypes(x int) {
+	// Without type information `Max` is assumed to be a type so it's not
+	// shared with the if statement.
+	a := Max
+	if x > Max { // want `missing whitespace above this line \(no shared variables above if\)`
+		fmt.Println("too large")
+	}
+
+	_ = a
+}
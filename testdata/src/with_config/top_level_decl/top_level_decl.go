@@ -0,0 +1,34 @@
+package testpkg
+
+import "fmt"
+const a = 1 // want `missing whitespace above this line \(top-level-decl\)`
+
+const b = 2
+const c = 3
+
+var d = 4 // Trailing comment belongs to d.
+// e is documented.
+const e = 5 // want `missing whitespace above this line \(top-level-decl\)`
+
+type T struct{}
+func (T) Method() {} // want `missing whitespace above this line \(top-level-decl\)`
+func (T) Other()  {}
+
+// fn is separated from its doc comment.
+// want +1 `unnecessary whitespace \(top-level-decl\)`
+
+func fn() {
+	fmt.Println(a, b, c, d, e)
+}
+
+// A Thing is separated from its doc comment.
+// want +1 `unnecessary whitespace \(top-level-decl\)`
+
+type Thing int
+
+// This is a section comment and not a doc comment.
+
+func sectionComment() {}
+
+// Section comment.
+var f = 6
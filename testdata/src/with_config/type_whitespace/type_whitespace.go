@@ -0,0 +1,60 @@
+package testpkg
+
+import (
+	"io"
+	"sync"
+)
+
+type Leading struct { // want +1 `unnecessary whitespace \(type-whitespace\)`
+
+	a int
+	b int
+}
+
+type Trailing struct {
+	a int
+	b int // want +1 `unnecessary whitespace \(type-whitespace\)`
+
+}
+
+type WithComments struct {
+	// a is documented.
+	a int
+
+	// b is documented.
+	b int // Trailing comment.
+}
+
+type Empty struct{}
+
+type Embedded struct {
+	sync.Mutex
+	name string // want `missing whitespace above this line \(embedded-field\)`
+}
+
+type EmbeddedSeparated struct {
+	sync.Mutex
+	io.Reader
+
+	name string
+}
+
+type EmbeddedWithDoc struct {
+	sync.Mutex // Protects name.
+	// name is documented.
+	name string // want `missing whitespace above this line \(embedded-field\)`
+	io.Writer   // want `missing whitespace above this line \(embedded-field\)`
+}
+
+type Interface interface { // want +1 `unnecessary whitespace \(type-whitespace\)`
+
+	io.Reader
+	Close() error // want `missing whitespace above this line \(embedded-field\)`
+}
+
+func fn() {
+	_ = struct { // want +1 `unnecessary whitespace \(type-whitespace\)`
+
+		a int
+	}{}
+}
@@ -0,0 +1,184 @@
+package wsl
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// checkTopLevelDecls checks the whitespace between the declarations in the
+// file. Declarations of different kinds (`import`, `const`, `var`, `type` and
+// `func`) must be separated by an empty line and doc comments must not be
+// separated from the declaration they document.
+func (w *WSL) checkTopLevelDecls() {
+	if _, ok := w.config.Checks[CheckTopLevelDecl]; !ok {
+		return
+	}
+
+	previousEnd := w.file.Name.End()
+
+	for i, decl := range w.file.Decls {
+		w.checkDocComment(decl, declName(decl), previousEnd, CheckTopLevelDecl)
+
+		if i > 0 {
+			previous := w.file.Decls[i-1]
+			if declKind(previous) != declKind(decl) {
+				w.checkNewlineBetween(previous, decl, CheckTopLevelDecl)
+			}
+		}
+
+		previousEnd = decl.End()
+	}
+}
+
+// checkDeclGroup checks the whitespace inside a grouped declaration, e.g.
+// `var ( ... )`. Leading and trailing empty lines are not allowed and doc
+// comments must not be separated from the spec they document.
+func (w *WSL) checkDeclGroup(decl *ast.GenDecl) {
+	if _, ok := w.config.Checks[CheckDeclGroup]; !ok {
+		return
+	}
+
+	if !decl.Lparen.IsValid() || len(decl.Specs) == 0 {
+		return
+	}
+
+	w.checkLeadingNewlineBefore(decl.Lparen, decl.Specs[0].Pos(), CheckDeclGroup)
+	w.checkTrailingNewlineAfter(decl.Specs[len(decl.Specs)-1].End(), decl.Rparen, CheckDeclGroup)
+
+	previousEnd := decl.Lparen
+
+	for _, spec := range decl.Specs {
+		w.checkDocComment(spec, specName(spec), previousEnd, CheckDeclGroup)
+		previousEnd = spec.End()
+	}
+}
+
+// checkNewlineBetween ensures there's an empty line between the end of
+// previous (including a comment on the same line) and the start of next
+// (including any comments above it).
+func (w *WSL) checkNewlineBetween(previous, next ast.Node, check CheckType) {
+	var (
+		previousEndLine = w.lineFor(previous.End())
+		nextContentPos  = next.Pos()
+	)
+
+	for _, cg := range w.comments.between(previous.End(), next.Pos()) {
+		// Comments on the same line as the end of the previous node belongs
+		// to the previous node.
+		if w.lineFor(cg.Pos()) == previousEndLine {
+			continue
+		}
+
+		nextContentPos = cg.Pos()
+
+		break
+	}
+
+	if w.lineFor(nextContentPos) > previousEndLine+1 {
+		return
+	}
+
+	insertPos := w.lineStartOf(nextContentPos)
+	w.addError(next.Pos(), insertPos, insertPos, ReasonMissingWhitespaceAbove, check)
+}
+
+// checkDocComment reports empty lines between a node and the comment above it
+// if the comment is a doc comment for the node that isn't attached because of
+// the empty lines. We only consider comments starting with the name of the
+// node (optionally prefixed with an article) as doc comments so we don't join
+// section comments with the following declaration.
+func (w *WSL) checkDocComment(node ast.Node, name string, previousEnd token.Pos, check CheckType) {
+	if name == "" || nodeDoc(node) != nil {
+		return
+	}
+
+	var doc *ast.CommentGroup
+
+	for _, cg := range w.comments.between(previousEnd, node.Pos()) {
+		if w.lineFor(cg.Pos()) == w.lineFor(previousEnd) {
+			continue
+		}
+
+		doc = cg
+	}
+
+	if doc == nil || !isDocCommentFor(doc, name) {
+		return
+	}
+
+	var (
+		docEndLine = w.lineFor(doc.End())
+		nodeLine   = w.lineFor(node.Pos())
+	)
+
+	if nodeLine <= docEndLine+1 {
+		return
+	}
+
+	file := w.fset.File(node.Pos())
+	w.addErrorRemoveNewline(file.LineStart(docEndLine+1), file.LineStart(nodeLine), check)
+}
+
+func isDocCommentFor(doc *ast.CommentGroup, name string) bool {
+	words := strings.Fields(doc.Text())
+	if len(words) > 1 {
+		switch words[0] {
+		case "A", "An", "The":
+			words = words[1:]
+		}
+	}
+
+	return len(words) > 0 && words[0] == name
+}
+
+func declKind(decl ast.Decl) token.Token {
+	if genDecl, ok := decl.(*ast.GenDecl); ok {
+		return genDecl.Tok
+	}
+
+	return token.FUNC
+}
+
+// declName returns the name of the declaration or an empty string if it
+// doesn't declare exactly one name.
+func declName(decl ast.Decl) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Name.Name
+	case *ast.GenDecl:
+		if d.Lparen.IsValid() || len(d.Specs) != 1 {
+			return ""
+		}
+
+		return specName(d.Specs[0])
+	default:
+		return ""
+	}
+}
+
+func specName(spec ast.Spec) string {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Name.Name
+	case *ast.ValueSpec:
+		return s.Names[0].Name
+	default:
+		return ""
+	}
+}
+
+func nodeDoc(node ast.Node) *ast.CommentGroup {
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		return n.Doc
+	case *ast.GenDecl:
+		return n.Doc
+	case *ast.TypeSpec:
+		return n.Doc
+	case *ast.ValueSpec:
+		return n.Doc
+	default:
+		return nil
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
-	"go/format"
+	"go/printer"
 	"go/token"
 	"go/types"
 	"math"
 	"slices"
+	"strings"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
 )
 
 const (
@@ -26,7 +29,9 @@ type fixRange struct {
 }
 
 type issue struct {
-	message string
+	message   string
+	reason    Reason
+	checkType CheckType
 	// We can report multiple fixes at the same position. This happens e.g. when
 	// we force error cuddling but the error assignment is already cuddled.
 	// See `checkError` for examples.
@@ -37,33 +42,104 @@ type WSL struct {
 	file     *ast.File
 	fset     *token.FileSet
 	typeInfo *types.Info
+	pkg      *types.Package
 	issues   map[token.Pos]issue
 	config   *Configuration
+	comments *commentIndex
+
+	// inspector is used to traverse the file. It's shared with other
+	// analyzers when running as an analyzer.
+	inspector *inspector.Inspector
 }
 
 func New(file *ast.File, pass *analysis.Pass, cfg *Configuration) *WSL {
+	w := newWSL(pass.Fset, file, pass.TypesInfo, pass.Pkg, cfg)
+
+	// The inspector is only available if the analyzer requires it.
+	if insp, ok := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector); ok {
+		w.inspector = insp
+	}
+
+	return w
+}
+
+func newWSL(
+	fset *token.FileSet,
+	file *ast.File,
+	info *types.Info,
+	pkg *types.Package,
+	cfg *Configuration,
+) *WSL {
+	if cfg.SyntaxOnly {
+		info, pkg = nil, nil
+	}
+
 	return &WSL{
-		fset:     pass.Fset,
+		fset:     fset,
 		file:     file,
-		typeInfo: pass.TypesInfo,
+		typeInfo: info,
+		pkg:      pkg,
 		issues:   make(map[token.Pos]issue),
 		config:   cfg,
+		comments: newCommentIndex(fset, file.Comments),
 	}
 }
 
 // Run will run analysis on the file and pass passed to the constructor. It's
 // typically only supposed to be used by [analysis.Analyzer].
 func (w *WSL) Run() {
-	ast.Inspect(w.file, func(n ast.Node) bool {
-		switch node := n.(type) {
-		case *ast.FuncDecl:
-			w.checkBlock(node.Body, NewCursor([]ast.Stmt{}))
-		case *ast.FuncLit:
-			w.checkBlock(node.Body, NewCursor([]ast.Stmt{}))
+	// Use the inspector shared with other analyzers if we have one, building
+	// one for a single file is more expensive than walking the file once.
+	if c, ok := w.fileCursor(); ok {
+		for c := range c.Preorder(
+			(*ast.FuncDecl)(nil),
+			(*ast.FuncLit)(nil),
+			(*ast.GenDecl)(nil),
+			(*ast.StructType)(nil),
+			(*ast.InterfaceType)(nil),
+			(*ast.CompositeLit)(nil),
+			(*ast.CallExpr)(nil),
+		) {
+			w.checkNode(c.Node())
 		}
+	} else {
+		ast.Inspect(w.file, func(n ast.Node) bool {
+			w.checkNode(n)
+			return true
+		})
+	}
 
-		return true
-	})
+	w.checkTopLevelDecls()
+	w.applyDirectives()
+}
+
+// fileCursor returns the cursor for the file in the inspector if there is one
+// and the file is part of it.
+func (w *WSL) fileCursor() (inspector.Cursor, bool) {
+	if w.inspector == nil {
+		return inspector.Cursor{}, false
+	}
+
+	return w.inspector.Root().FindNode(w.file)
+}
+
+func (w *WSL) checkNode(n ast.Node) {
+	switch node := n.(type) {
+	case *ast.FuncDecl:
+		w.checkBlock(node.Body, NewCursor([]ast.Stmt{}))
+	case *ast.FuncLit:
+		w.checkBlock(node.Body, NewCursor([]ast.Stmt{}))
+	case *ast.GenDecl:
+		w.checkDeclGroup(node)
+	case *ast.StructType:
+		w.checkFieldList(node.Fields)
+	case *ast.InterfaceType:
+		w.checkFieldList(node.Methods)
+	case *ast.CompositeLit:
+		w.checkCompositeLit(node)
+	case *ast.CallExpr:
+		w.checkCallArgs(node)
+	}
 }
 
 func (w *WSL) checkStmt(stmt ast.Stmt, cursor *Cursor) {
@@ -135,8 +211,15 @@ func (w *WSL) checkBodyBlock(block *ast.BlockStmt) {
 	w.walkBody(NewBlockCursor(block.List, w.lineFor(block.Rbrace)))
 }
 
-func (w *WSL) checkBodyStmts(stmts []ast.Stmt) {
-	w.walkBody(NewCursor(stmts))
+// checkCaseBody checks the statements in a case or comm clause. The clause ends
+// at the next clause or the closing brace of the switch or select statement.
+func (w *WSL) checkCaseBody(stmts []ast.Stmt, cursor *Cursor) {
+	endLine := cursor.rbraceLine
+	if nextClause := cursor.NextNode(); nextClause != nil {
+		endLine = w.lineFor(nextClause.Pos())
+	}
+
+	w.walkBody(NewBlockCursor(stmts, endLine))
 }
 
 func (w *WSL) walkBody(cursor *Cursor) {
@@ -217,7 +300,7 @@ func (w *WSL) checkCuddlingMaxAllowed(
 	// respect the requirement to use the idiomatic err checking and never
 	// insert a newline between the err and if.
 	_, errEnabled := w.config.Checks[CheckErr]
-	errIdent := w.isErrNotNilCheck(stmt)
+	errIdent := w.errCheckIdent(stmt, previousStmtNode)
 
 	if errEnabled && errIdent != nil && identsIntersect([]*ast.Ident{errIdent}, previousIdents) {
 		if numStmtsAbove > 1 {
@@ -492,7 +575,7 @@ func (w *WSL) checkNewlineAfter(
 					reportPos,
 					insertPos,
 					insertPos,
-					messageMissingWhitespaceBelow,
+					ReasonMissingWhitespaceBelow,
 					check,
 				)
 			}
@@ -511,11 +594,7 @@ func (w *WSL) checkNewlineAfter(
 	nextContentLine := w.lineFor(nextContentPos)
 
 	// Find the first comment between the boundary and the next statement.
-	for _, cg := range w.file.Comments {
-		if cg.End() <= boundary.End() {
-			continue
-		}
-
+	for _, cg := range w.comments.after(boundary.End()) {
 		// Skip comments that are inside the current statement but after the
 		// boundary. This handles cases like comments inside an else block when
 		// checking the if-body.
@@ -542,7 +621,7 @@ func (w *WSL) checkNewlineAfter(
 			reportPos,
 			insertPos,
 			insertPos,
-			messageMissingWhitespaceBelow,
+			ReasonMissingWhitespaceBelow,
 			check,
 		)
 	}
@@ -555,7 +634,7 @@ func (w *WSL) checkCaseClause(stmt *ast.CaseClause, cursor *Cursor) {
 		w.checkCaseTrailingNewline(stmt.Body, cursor)
 	}
 
-	w.checkBodyStmts(stmt.Body)
+	w.checkCaseBody(stmt.Body, cursor)
 }
 
 func (w *WSL) checkCommClause(stmt *ast.CommClause, cursor *Cursor) {
@@ -565,7 +644,7 @@ func (w *WSL) checkCommClause(stmt *ast.CommClause, cursor *Cursor) {
 		w.checkCaseTrailingNewline(stmt.Body, cursor)
 	}
 
-	w.checkBodyStmts(stmt.Body)
+	w.checkCaseBody(stmt.Body, cursor)
 }
 
 func (w *WSL) checkAssign(stmt *ast.AssignStmt, cursor *Cursor) {
@@ -770,7 +849,7 @@ func (w *WSL) checkError(
 
 	defer cursor.Save()()
 
-	errIdent := w.isErrNotNilCheck(ifStmt)
+	errIdent := w.errCheckIdent(ifStmt, previousNode)
 	if errIdent == nil {
 		return
 	}
@@ -804,12 +883,8 @@ func (w *WSL) checkError(
 	previousEndLine := w.lineFor(previousNode.End())
 
 	// Check for comments on the same line as the previous node (extends effective end line).
-	for _, cg := range w.file.Comments {
-		if cg.Pos() >= ifStmt.Pos() {
-			break
-		}
-
-		if cg.Pos() < previousNode.End() || cg.End() > ifStmt.Pos() {
+	for _, cg := range w.comments.between(previousNode.End(), ifStmt.Pos()) {
+		if cg.End() > ifStmt.Pos() {
 			continue
 		}
 
@@ -849,7 +924,7 @@ func (w *WSL) checkError(
 	// Add whitespace above the error assignment if there's a statement above.
 	if w.numberOfStatementsAbove(cursor) > 0 {
 		insertPos := w.lineStartOf(previousNode.Pos())
-		w.addError(removeStart, insertPos, insertPos, messageMissingWhitespaceAbove, cursor.checkType)
+		w.addError(removeStart, insertPos, insertPos, ReasonMissingWhitespaceAbove, cursor.checkType)
 	}
 }
 
@@ -1110,15 +1185,11 @@ func (w *WSL) checkCaseTrailingNewline(body []ast.Stmt, cursor *Cursor) {
 		lastLeftAlignedCommentEnd    = token.NoPos
 	)
 
-	for _, commentGroup := range w.file.Comments {
+	for _, commentGroup := range w.comments.after(lastStmt.End()) {
 		if commentGroup.Pos() >= nextCase.Pos() {
 			break
 		}
 
-		if commentGroup.End() <= lastStmt.End() {
-			continue
-		}
-
 		for _, comment := range commentGroup.List {
 			commentLine := w.lineFor(comment.Pos())
 			if commentLine <= lastStmtEndLine || commentLine >= nextCaseLine {
@@ -1159,7 +1230,7 @@ func (w *WSL) checkCaseTrailingNewline(body []ast.Stmt, cursor *Cursor) {
 	}
 
 	insertPos := w.lineStartOf(nextCaseOrLeftAlignedComment)
-	w.addError(lastStmtOrCommentEnd, insertPos, insertPos, messageMissingWhitespaceBelow, CheckCaseTrailingNewline)
+	w.addError(lastStmtOrCommentEnd, insertPos, insertPos, ReasonMissingWhitespaceBelow, CheckCaseTrailingNewline)
 }
 
 func (w *WSL) checkBlockLeadingNewline(body *ast.BlockStmt) {
@@ -1175,38 +1246,36 @@ func (w *WSL) checkCommLeadingNewline(commClause *ast.CommClause) {
 }
 
 func (w *WSL) checkLeadingNewline(startPos token.Pos, body []ast.Stmt) {
-	if _, ok := w.config.Checks[CheckLeadingWhitespace]; !ok {
+	if len(body) == 0 {
 		return
 	}
 
-	if len(body) == 0 {
+	w.checkLeadingNewlineBefore(startPos, body[0].Pos(), CheckLeadingWhitespace)
+}
+
+// checkLeadingNewlineBefore reports empty lines between the opening token at
+// startPos and the first content at firstPos. Comments between the two are
+// considered content, but empty lines between the comments and firstPos are
+// also reported.
+func (w *WSL) checkLeadingNewlineBefore(startPos, firstPos token.Pos, check CheckType) {
+	if _, ok := w.config.Checks[check]; !ok {
 		return
 	}
 
 	var (
 		openLine        = w.lineFor(startPos)
-		firstStmtPos    = body[0].Pos()
+		firstStmtPos    = firstPos
 		firstStmtLine   = w.lineFor(firstStmtPos)
-		leadingComments []*ast.CommentGroup
+		leadingComments = w.comments.between(startPos+1, firstStmtPos)
 	)
 
-	for _, cg := range w.file.Comments {
-		if cg.Pos() >= firstStmtPos {
-			break
-		}
-
-		if cg.Pos() > startPos {
-			leadingComments = append(leadingComments, cg)
-		}
-	}
-
 	if len(leadingComments) == 0 {
 		if firstStmtLine := w.lineFor(firstStmtPos); firstStmtLine > openLine+1 {
 			file := w.fset.File(startPos)
 			w.addErrorRemoveNewline(
 				file.LineStart(openLine+1),
 				file.LineStart(firstStmtLine),
-				CheckLeadingWhitespace,
+				check,
 			)
 		}
 
@@ -1238,7 +1307,7 @@ func (w *WSL) checkLeadingNewline(startPos token.Pos, body []ast.Stmt) {
 		w.addErrorRemoveNewline(
 			file.LineStart(openLine+1),
 			file.LineStart(firstContentLine),
-			CheckLeadingWhitespace,
+			check,
 		)
 	}
 
@@ -1247,16 +1316,12 @@ func (w *WSL) checkLeadingNewline(startPos token.Pos, body []ast.Stmt) {
 		w.addErrorRemoveNewline(
 			file.LineStart(lastCommentEndLine+1),
 			file.LineStart(firstStmtLine),
-			CheckLeadingWhitespace,
+			check,
 		)
 	}
 }
 
 func (w *WSL) checkTrailingNewline(body *ast.BlockStmt) {
-	if _, ok := w.config.Checks[CheckTrailingWhitespace]; !ok {
-		return
-	}
-
 	if len(body.List) == 0 {
 		return
 	}
@@ -1278,29 +1343,36 @@ func (w *WSL) checkTrailingNewline(body *ast.BlockStmt) {
 		}
 	}
 
-	// Find the last comment after last statement using position comparison.
-	for _, cg := range w.file.Comments {
-		if cg.End() <= lastContentPos {
-			continue
-		}
+	w.checkTrailingNewlineAfter(lastContentPos, body.Rbrace, CheckTrailingWhitespace)
+}
+
+// checkTrailingNewlineAfter reports empty lines between the last content
+// ending at lastContentPos and the closing token at closePos. Comments after
+// the last content are considered content.
+func (w *WSL) checkTrailingNewlineAfter(lastContentPos, closePos token.Pos, check CheckType) {
+	if _, ok := w.config.Checks[check]; !ok {
+		return
+	}
 
-		if cg.Pos() >= body.Rbrace {
+	// Find the last comment after last statement using position comparison.
+	for _, cg := range w.comments.after(lastContentPos) {
+		if cg.Pos() >= closePos {
 			break
 		}
 
-		if cg.End() < body.Rbrace {
+		if cg.End() < closePos {
 			lastContentPos = cg.End()
 		}
 	}
 
-	closingLine := w.lineFor(body.Rbrace)
+	closingLine := w.lineFor(closePos)
 	lastContentLine := w.lineFor(lastContentPos)
 
 	if closingLine > lastContentLine+1 {
-		file := w.fset.File(body.Rbrace)
+		file := w.fset.File(closePos)
 		removeStart := file.LineStart(lastContentLine + 1)
 		removeEnd := file.LineStart(closingLine)
-		w.addErrorRemoveNewline(removeStart, removeEnd, CheckTrailingWhitespace)
+		w.addErrorRemoveNewline(removeStart, removeEnd, check)
 	}
 }
 
@@ -1351,23 +1423,33 @@ func (w *WSL) maybeGroupDecl(stmt *ast.DeclStmt, cursor *Cursor) bool {
 		lastNode = nextNode
 	}
 
+	// Print the specs the same way as gofmt indented as the statement, assuming
+	// the file is indented with tabs. The specs are printed one by one since
+	// the printer would keep the lines between them from the original
+	// declarations.
+	indent := w.fset.PositionFor(firstNode.Pos(), false).Column - 1
+	printerConfig := printer.Config{
+		Mode:     printer.UseSpaces | printer.TabIndent,
+		Tabwidth: 8,
+		Indent:   indent + 1,
+	}
+
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf, "%s (\n", firstNode.Tok)
 
 	for _, spec := range specs {
-		var specBuf bytes.Buffer
-		if err := format.Node(&specBuf, w.fset, spec); err != nil {
+		if err := printerConfig.Fprint(&buf, w.fset, spec); err != nil {
 			return false
 		}
 
-		buf.Write(specBuf.Bytes())
 		buf.WriteByte('\n')
 	}
 
-	buf.WriteByte(')')
+	buf.WriteString(strings.Repeat("\t", indent) + ")")
 
 	// We add a diagnostic to every subsequent statement to properly represent
-	// the violations. Duplicate fixes for the same range is fine.
+	// the violations. The duplicated fix is only kept for the first issue, see
+	// dedupeEdits.
 	for _, n := range reportNodes {
 		w.addErrorWithMessageAndFix(
 			n.Pos(),
@@ -1375,6 +1457,8 @@ func (w *WSL) maybeGroupDecl(stmt *ast.DeclStmt, cursor *Cursor) bool {
 			lastNode.End(),
 			fmt.Sprintf("%s (never cuddle %s)", messageMissingWhitespaceAbove, CheckDecl),
 			buf.Bytes(),
+			ReasonNeverCuddle,
+			CheckDecl,
 		)
 	}
 
@@ -1436,10 +1520,17 @@ func (w *WSL) lineStartOf(pos token.Pos) token.Pos {
 	return w.fset.File(pos).LineStart(w.lineFor(pos))
 }
 
-func (w *WSL) implementsErr(node *ast.Ident) bool {
+func (w *WSL) implementsErr(node ast.Expr) bool {
+	if w.typeInfo == nil {
+		return looksLikeErr(node)
+	}
+
+	// The type is unknown if the file is type checked on its own and the
+	// expression depends on another file, e.g. the result of a function
+	// declared there. Fall back to the name like without type information.
 	typeInfo := w.typeInfo.TypeOf(node)
-	if typeInfo == nil {
-		return false
+	if typeInfo == nil || typeInfo == types.Typ[types.Invalid] {
+		return looksLikeErr(node)
 	}
 
 	errorType, ok := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
@@ -1451,23 +1542,10 @@ func (w *WSL) implementsErr(node *ast.Ident) bool {
 }
 
 func (w *WSL) commentOnLineAfterNodePos(node ast.Node) token.Pos {
-	nodeEndLine := w.lineFor(node.End())
-
-	for _, cg := range w.file.Comments {
-		if cg.End() <= node.End() {
-			continue
-		}
-
-		commentLine := w.lineFor(cg.Pos())
-		if commentLine == nodeEndLine {
-			continue
-		}
-
-		if commentLine == nodeEndLine+1 {
-			return cg.Pos()
-		}
-
-		break
+	// Comments starting on the same line as the end of the node can't span to
+	// the next line since a comment following code ends the comment group.
+	if cg := w.comments.onLine(w.lineFor(node.End()) + 1); cg != nil {
+		return cg.Pos()
 	}
 
 	return token.NoPos
@@ -1476,58 +1554,73 @@ func (w *WSL) commentOnLineAfterNodePos(node ast.Node) token.Pos {
 func (w *WSL) addErrorInvalidTypeCuddle(pos token.Pos, ct CheckType) {
 	reportMessage := fmt.Sprintf("%s (invalid statement above %s)", messageMissingWhitespaceAbove, ct)
 	insertPos := w.lineStartOf(pos)
-	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage)
+	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage, ReasonInvalidTypeCuddle, ct)
 }
 
 func (w *WSL) addErrorTooManyStatements(pos token.Pos, ct CheckType) {
 	reportMessage := fmt.Sprintf("%s (too many statements above %s)", messageMissingWhitespaceAbove, ct)
 	insertPos := w.lineStartOf(pos)
-	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage)
+	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage, ReasonTooManyStatements, ct)
 }
 
 func (w *WSL) addErrorNoIntersection(pos token.Pos, ct CheckType) {
 	reportMessage := fmt.Sprintf("%s (no shared variables above %s)", messageMissingWhitespaceAbove, ct)
 	insertPos := w.lineStartOf(pos)
-	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage)
+	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage, ReasonNoIntersection, ct)
 }
 
 func (w *WSL) addErrorVariableNotShared(pos token.Pos, ct CheckType) {
 	reportMessage := fmt.Sprintf("%s (variable not shared with %s)", messageMissingWhitespaceAbove, ct)
 	insertPos := w.lineStartOf(pos)
-	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage)
+	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage, ReasonVariableNotShared, ct)
 }
 
 func (w *WSL) addErrorTooManyLines(pos token.Pos, ct CheckType) {
 	reportMessage := fmt.Sprintf("%s (too many lines above %s)", messageMissingWhitespaceAbove, ct)
 	insertPos := w.lineStartOf(pos)
-	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage)
+	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage, ReasonTooManyLines, ct)
 }
 
 func (w *WSL) addErrorNeverAllow(pos token.Pos, ct CheckType) {
 	reportMessage := fmt.Sprintf("%s (never cuddle %s)", messageMissingWhitespaceAbove, ct)
 	insertPos := w.lineStartOf(pos)
-	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage)
+	w.addErrorWithMessage(pos, insertPos, insertPos, reportMessage, ReasonNeverCuddle, ct)
 }
 
-func (w *WSL) addError(report, start, end token.Pos, message string, ct CheckType) {
-	reportMessage := fmt.Sprintf("%s (%s)", message, ct)
-	w.addErrorWithMessage(report, start, end, reportMessage)
+// addError adds an error with the message for the reason, this should only be
+// used for ReasonMissingWhitespaceAbove and ReasonMissingWhitespaceBelow.
+func (w *WSL) addError(report, start, end token.Pos, reason Reason, ct CheckType) {
+	reportMessage := fmt.Sprintf("%s (%s)", reason.message(), ct)
+	w.addErrorWithMessage(report, start, end, reportMessage, reason, ct)
 }
 
 func (w *WSL) addErrorRemoveNewline(start, end token.Pos, ct CheckType) {
 	reportMessage := fmt.Sprintf("%s (%s)", messageRemoveWhitespace, ct)
-	w.addErrorWithMessageAndFix(start, start, end, reportMessage, []byte{})
+	w.addErrorWithMessageAndFix(start, start, end, reportMessage, []byte{}, ReasonRemoveWhitespace, ct)
 }
 
-func (w *WSL) addErrorWithMessage(report, start, end token.Pos, message string) {
-	w.addErrorWithMessageAndFix(report, start, end, message, []byte("\n"))
+func (w *WSL) addErrorWithMessage(
+	report, start, end token.Pos,
+	message string,
+	reason Reason,
+	ct CheckType,
+) {
+	w.addErrorWithMessageAndFix(report, start, end, message, []byte("\n"), reason, ct)
 }
 
-func (w *WSL) addErrorWithMessageAndFix(report, start, end token.Pos, message string, fix []byte) {
+func (w *WSL) addErrorWithMessageAndFix(
+	report, start, end token.Pos,
+	message string,
+	fix []byte,
+	reason Reason,
+	ct CheckType,
+) {
 	iss, ok := w.issues[report]
 	if !ok {
 		iss = issue{
 			message:   message,
+			reason:    reason,
+			checkType: ct,
 			fixRanges: []fixRange{},
 		}
 	}
@@ -1671,6 +1764,10 @@ func (w *WSL) identsFromNode(node ast.Node, skipBlock bool) []*ast.Ident {
 		seen[name] = struct{}{}
 	}
 
+	// Without type information we need to know what selector an identifier is
+	// part of to tell package names and fields apart.
+	selectors := map[*ast.Ident]*ast.SelectorExpr{}
+
 	ast.Inspect(node, func(n ast.Node) bool {
 		if skipBlock {
 			if _, ok := n.(*ast.BlockStmt); ok {
@@ -1678,11 +1775,27 @@ func (w *WSL) identsFromNode(node ast.Node, skipBlock bool) []*ast.Ident {
 			}
 		}
 
+		if sel, ok := n.(*ast.SelectorExpr); ok && w.typeInfo == nil {
+			selectors[sel.Sel] = sel
+
+			if x, ok := sel.X.(*ast.Ident); ok {
+				selectors[x] = sel
+			}
+		}
+
 		ident, ok := n.(*ast.Ident)
 		if !ok {
 			return true
 		}
 
+		if w.typeInfo == nil {
+			if !w.isTypeLikeIdent(ident, selectors[ident]) {
+				addIdent(ident)
+			}
+
+			return true
+		}
+
 		// Prefer Uses over Defs; fall back to Defs if not a use site.
 		var typesObject types.Object
 		if obj := w.typeInfo.Uses[ident]; obj != nil {
@@ -1753,94 +1866,3 @@ func (w *WSL) identsFromCaseArms(node ast.Node) []*ast.Ident {
 
 	return idents
 }
-
-// hasSelectorCall checks if node contains a selector call with one of the given names.
-func hasSelectorCall(node ast.Node, selectorNames []string) bool {
-	var found bool
-
-	ast.Inspect(node, func(n ast.Node) bool {
-		if found {
-			return false // Already found
-		}
-
-		if _, ok := n.(*ast.BlockStmt); ok {
-			return false
-		}
-
-		if sel, ok := n.(*ast.SelectorExpr); ok {
-			found = slices.Contains(selectorNames, sel.Sel.Name)
-			return false
-		}
-
-		return true
-	})
-
-	return found
-}
-
-func (w *WSL) isLockOrUnlock(current, previous ast.Node) bool {
-	// If we're an ExprStmt (e.g. X()), we check if we're calling `Unlock` or
-	// `RWUnlock`. No matter how deep this is or what previous statement was, we
-	// allow this.
-	//
-	// mu.Lock()
-	// [ANY BLOCK]
-	// mu.Unlock()
-	if _, ok := current.(*ast.ExprStmt); ok {
-		return hasSelectorCall(current, []string{"Unlock", "RWUnlock"})
-	}
-
-	if previous != nil {
-		return hasSelectorCall(previous, []string{"Lock", "RWLock", "TryLock"})
-	}
-
-	return false
-}
-
-// isErrNotNilCheck returns the error identifier if stmt is an `if err != nil`
-// or `if err == nil` check without an init statement, nil otherwise.
-func (w *WSL) isErrNotNilCheck(stmt ast.Node) *ast.Ident {
-	ifStmt, ok := stmt.(*ast.IfStmt)
-	if !ok {
-		return nil
-	}
-
-	// If the error checking has an init condition (e.g. if err := f();) we
-	// don't consider it an error check since the error is assigned on this row.
-	if ifStmt.Init != nil {
-		return nil
-	}
-
-	// The condition must be a binary expression (X OP Y)
-	binaryExpr, ok := ifStmt.Cond.(*ast.BinaryExpr)
-	if !ok {
-		return nil
-	}
-
-	// We must do not equal or equal comparison (!= or ==)
-	if binaryExpr.Op != token.NEQ && binaryExpr.Op != token.EQL {
-		return nil
-	}
-
-	xIdent, ok := binaryExpr.X.(*ast.Ident)
-	if !ok {
-		return nil
-	}
-
-	// X is not an error so it's not error checking
-	if !w.implementsErr(xIdent) {
-		return nil
-	}
-
-	yIdent, ok := binaryExpr.Y.(*ast.Ident)
-	if !ok {
-		return nil
-	}
-
-	// Y is not compared with `nil`
-	if yIdent.Name != "nil" {
-		return nil
-	}
-
-	return xIdent
-}
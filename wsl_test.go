@@ -0,0 +1,359 @@
+package wsl
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// nestedSource generates a function with blocks nested depth levels deep.
+func nestedSource(depth int) string {
+	var sb strings.Builder
+
+	sb.WriteString("package main\n\nfunc main() {\n\tx := 0\n")
+
+	for i := range depth {
+		indent := strings.Repeat("\t", i+1)
+
+		switch i % 4 {
+		case 0:
+			fmt.Fprintf(&sb, "%sx++\n%sif x > %d {\n", indent, indent, i)
+		case 1:
+			fmt.Fprintf(&sb, "%sy := x\n%sfor i := 0; i < y; i++ {\n", indent, indent)
+		case 2:
+			fmt.Fprintf(&sb, "%sfn := func() {\n%s\tx--\n%s}\n%sfn()\n%sswitch {\n%scase x > 0:\n", indent, indent, indent, indent, indent, indent)
+		case 3:
+			fmt.Fprintf(&sb, "%s\tvar a int\n%s\ta = x\n%s\tfor range a {\n", indent, indent, indent)
+		}
+	}
+
+	for i := depth - 1; i >= 0; i-- {
+		indent := strings.Repeat("\t", i+1)
+
+		switch i % 4 {
+		case 2:
+			fmt.Fprintf(&sb, "%s}\n", indent)
+		case 3:
+			fmt.Fprintf(&sb, "%s\t}\n", indent)
+		default:
+			fmt.Fprintf(&sb, "%s}\n", indent)
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// largeSource generates a file with n functions mixing all kinds of
+// statements, both properly formatted and cuddled.
+func largeSource(n int) string {
+	var sb strings.Builder
+
+	sb.WriteString("package main\n\nimport (\n\t\"errors\"\n\t\"fmt\"\n\t\"sync\"\n)\n\n")
+
+	for i := range n {
+		fmt.Fprintf(&sb, `type T%[1]d struct {
+	sync.Mutex
+	a int
+}
+
+func (t *T%[1]d) Fn(items []int) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+	sum := 0
+	for _, item := range items {
+		if item < 0 {
+			continue
+		}
+		sum += item
+	}
+	var err error
+	err = errors.New("x")
+
+	if err != nil {
+		return 0, err
+	}
+	switch sum {
+	case 0:
+		fmt.Println("zero")
+	default:
+		t.a = sum
+	}
+	go func() {
+		fmt.Println(sum)
+	}()
+	sum++
+	return sum, nil
+}
+
+`, i)
+	}
+
+	return sb.String()
+}
+
+// BenchmarkRun runs all checks on generated files both walking the file and
+// using an inspector shared with other analyzers as when running as an
+// analyzer.
+func BenchmarkRun(b *testing.B) {
+	for _, tc := range []struct {
+		name string
+		src  string
+	}{
+		{name: "small", src: lintSource},
+		{name: "nested", src: nestedSource(200)},
+		{name: "large", src: largeSource(2000)},
+	} {
+		fset := token.NewFileSet()
+
+		file, err := parser.ParseFile(fset, "main.go", tc.src, parser.ParseComments)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		info, pkg := typeCheckFile(fset, file)
+		cfg := NewConfig()
+		cfg.Checks = AllChecks()
+
+		for _, variant := range []struct {
+			suffix    string
+			inspector *inspector.Inspector
+		}{
+			{},
+			{suffix: "-inspector", inspector: inspector.New([]*ast.File{file})},
+		} {
+			b.Run(tc.name+variant.suffix, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(tc.src)))
+
+				for b.Loop() {
+					w := newWSL(fset, file, info, pkg, cfg)
+					w.inspector = variant.inspector
+					w.Run()
+				}
+			})
+		}
+	}
+}
+
+// FuzzWSL checks that applying all fixes to a formatted file results in a file
+// that parses, is formatted, has the same syntax tree except for positions and
+// grouped declarations and has no issues left except unused trailing
+// directives. Each file is linted both with and without type information. It's
+// seeded with all Go files in testdata/src.
+func FuzzWSL(f *testing.F) {
+	err := filepath.WalkDir(filepath.Join("testdata", "src"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".go" {
+			return err
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		f.Add(src)
+
+		return nil
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	allChecks := NewConfig()
+	allChecks.Checks = AllChecks()
+
+	configs := []struct {
+		name  string
+		cfg   *Configuration
+		typed bool
+	}{
+		{name: "default", cfg: NewConfig()},
+		{name: "all", cfg: allChecks},
+		{name: "default-typed", cfg: NewConfig(), typed: true},
+		{name: "all-typed", cfg: allChecks, typed: true},
+	}
+
+	f.Fuzz(func(t *testing.T, src []byte) {
+		src, err := format.Source(src)
+		if err != nil {
+			return
+		}
+
+		for _, tc := range configs {
+			name := tc.name
+
+			lint := func(fset *token.FileSet, file *ast.File) []Issue {
+				var info *types.Info
+				if tc.typed {
+					info, _ = typeCheckFile(fset, file)
+				}
+
+				return Lint(fset, file, info, tc.cfg)
+			}
+
+			fset := token.NewFileSet()
+
+			file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments|parser.SkipObjectResolution)
+			if err != nil {
+				return
+			}
+
+			var edits []TextEdit
+			for _, issue := range lint(fset, file) {
+				edits = append(edits, issue.Edits...)
+			}
+
+			if len(edits) == 0 {
+				continue
+			}
+
+			fixed := applyTextEdits(fset.File(file.Pos()), src, edits)
+
+			fixedFset := token.NewFileSet()
+
+			fixedFile, err := parser.ParseFile(fixedFset, "main.go", fixed, parser.ParseComments|parser.SkipObjectResolution)
+			if err != nil {
+				t.Fatalf("%s: fixed source doesn't parse: %v\n%s", name, err, fixed)
+			}
+
+			formatted, err := format.Source(fixed)
+			if err != nil {
+				t.Fatalf("%s: fixed source can't be formatted: %v\n%s", name, err, fixed)
+			}
+
+			// Adding or removing lines can change the alignment of comments
+			// and values on other lines which gofmt will adjust, but it should
+			// never change any lines.
+			if !slices.EqualFunc(bytes.Split(formatted, []byte("\n")), bytes.Split(fixed, []byte("\n")), equalFields) {
+				t.Fatalf("%s: fixed source isn't formatted\n%s", name, fixed)
+			}
+
+			// The only issues without a fix are unused trailing directives
+			// since they can't be removed without the comment they're part of,
+			// all other issues must be gone.
+			for _, issue := range lint(fixedFset, fixedFile) {
+				if issue.CheckType != CheckUnusedDirective || len(issue.Edits) > 0 {
+					t.Fatalf("%s: issue after fix at %s: %s\n%s",
+						name, fixedFset.Position(issue.Pos), issue.Message, fixed)
+				}
+			}
+
+			want := strings.Split(dumpSyntax(t, file), "\n")
+			got := strings.Split(dumpSyntax(t, fixedFile), "\n")
+
+			if !slices.Equal(want, got) {
+				i := 0
+				for i < min(len(want), len(got)) && want[i] == got[i] {
+					i++
+				}
+
+				t.Fatalf("%s: fixed syntax tree differs at line %d, want %q, got %q\n%s",
+					name, i+1, lineAt(want, i), lineAt(got, i), fixed)
+			}
+		}
+	})
+}
+
+// lineAt returns the line at index i or an empty string if there's no such
+// line.
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+
+	return ""
+}
+
+// equalFields reports if a and b are equal ignoring the amount of whitespace
+// between fields.
+func equalFields(a, b []byte) bool {
+	return slices.EqualFunc(bytes.Fields(a), bytes.Fields(b), bytes.Equal)
+}
+
+// dumpSyntax returns the syntax tree of the file without positions and with all
+// grouped declarations in statements split to one declaration per spec. The
+// text of all comments except directives is added at the end since fixes can't
+// change comments.
+// The file is modified and can't be used after this.
+func dumpSyntax(t *testing.T, file *ast.File) string {
+	t.Helper()
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		block, ok := n.(*ast.BlockStmt)
+		if !ok {
+			return true
+		}
+
+		var list []ast.Stmt
+
+		for _, stmt := range block.List {
+			decl, ok := stmt.(*ast.DeclStmt)
+			if !ok {
+				list = append(list, stmt)
+				continue
+			}
+
+			genDecl, ok := decl.Decl.(*ast.GenDecl)
+			if !ok {
+				list = append(list, stmt)
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				list = append(list, &ast.DeclStmt{
+					Decl: &ast.GenDecl{Tok: genDecl.Tok, Specs: []ast.Spec{spec}},
+				})
+			}
+		}
+
+		block.List = list
+
+		return true
+	})
+
+	var sb strings.Builder
+
+	err := ast.Fprint(&sb, nil, file, func(_ string, v reflect.Value) bool {
+		if !ast.NotNilFilter("", v) {
+			return false
+		}
+
+		switch v.Interface().(type) {
+		case token.Pos, *ast.CommentGroup, []*ast.CommentGroup:
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cg := range file.Comments {
+		for _, c := range cg.List {
+			// Unused directives are removed.
+			if _, _, ok := parseDirective(c.Text); ok {
+				continue
+			}
+
+			sb.WriteString(c.Text + "\n")
+		}
+	}
+
+	return sb.String()
+}